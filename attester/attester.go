@@ -0,0 +1,336 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package attester provides a software attester that produces fresh, signed
+Entity Attestation Tokens from a claims template.  It is a local stand-in for
+real devices, in tests or wherever a hardware root of trust is not available.
+*/
+package attester
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/veraison/eat"
+	cose "github.com/veraison/go-cose"
+)
+
+// Format is the serialization of the produced tokens
+type Format int
+
+const (
+	// FormatCWT produces a COSE_Sign1 wrapped in a CWT tag
+	FormatCWT Format = iota
+	// FormatJWT produces a JWT in JWS compact serialization
+	FormatJWT
+)
+
+// ctiSize is the size of the generated cti claim
+const ctiSize = 16
+
+// ueidSize is the size of the random part of the generated UEID
+const ueidSize = 16
+
+// Attester signs fresh EATs built from a claims template
+type Attester struct {
+	key      crypto.Signer
+	signer   cose.Signer
+	kid      []byte
+	template eat.Eat
+	ueid     eat.UEID
+	submods  map[string]*Attester
+	rand     io.Reader
+	now      func() time.Time
+}
+
+// Option sets optional parameters of an Attester
+type Option func(*Attester) error
+
+// WithKeyID sets the kid of the attestation key, carried in the header of the
+// produced tokens
+func WithKeyID(kid []byte) Option {
+	return func(a *Attester) error {
+		a.kid = kid
+		return nil
+	}
+}
+
+// WithUEID sets the UEID of the attester.  If neither this option nor the
+// template set a UEID, a random one is generated when the Attester is created.
+func WithUEID(ueid eat.UEID) Option {
+	return func(a *Attester) error {
+		if err := ueid.Validate(); err != nil {
+			return fmt.Errorf("UEID: %w", err)
+		}
+		a.ueid = ueid
+		return nil
+	}
+}
+
+// WithSubmod adds a nested attester, e.g. a TEE within a platform, whose token
+// is produced for the same challenge and embedded in the named submod
+func WithSubmod(name string, sub *Attester) Option {
+	return func(a *Attester) error {
+		if sub == nil {
+			return fmt.Errorf("submod %q: nil attester", name)
+		}
+		if _, ok := a.submods[name]; ok {
+			return fmt.Errorf("duplicate submod %q", name)
+		}
+		a.submods[name] = sub
+		return nil
+	}
+}
+
+// WithRand sets the source of randomness used for signing and for generating
+// cti claims.  The default is crypto/rand.
+func WithRand(r io.Reader) Option {
+	return func(a *Attester) error {
+		a.rand = r
+		return nil
+	}
+}
+
+// WithClock sets the function that provides the iat claim.  The default is
+// time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(a *Attester) error {
+		a.now = now
+		return nil
+	}
+}
+
+// New returns an Attester that signs the claims in the supplied template with
+// the supplied key, which must be an ECDSA (P-256, P-384 or P-521), Ed25519 or
+// RSA key.  The nonce, ueid, iat and cti claims of the template are replaced in
+// each token.
+//
+//nolint:gocritic
+func New(key crypto.Signer, template eat.Eat, opts ...Option) (*Attester, error) {
+	alg, err := signatureAlgorithm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := cose.NewSigner(alg, key)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Attester{
+		key:      key,
+		signer:   signer,
+		template: template,
+		submods:  make(map[string]*Attester),
+		rand:     rand.Reader,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&a); err != nil {
+			return nil, err
+		}
+	}
+
+	if template.Submods != nil {
+		for name := range a.submods {
+			if _, ok := (*template.Submods)[name]; ok {
+				return nil, fmt.Errorf("submod %q is also in the template", name)
+			}
+		}
+	}
+
+	switch {
+	case a.ueid != nil:
+	case template.UEID != nil:
+		a.ueid = *template.UEID
+	default:
+		if a.ueid, err = eat.NewRandUEID(ueidSize); err != nil {
+			return nil, err
+		}
+	}
+
+	return &a, nil
+}
+
+// NewFromPEMFile returns an Attester (see New) that signs with the private key
+// in the supplied PEM file, in PKCS #8, SEC 1 or PKCS #1 format
+//
+//nolint:gocritic
+func NewFromPEMFile(path string, template eat.Eat, opts ...Option) (*Attester, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return New(key, template, opts...)
+}
+
+func parsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		key any
+		err error
+	)
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+func signatureAlgorithm(key crypto.Signer) (cose.Algorithm, error) {
+	switch k := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return cose.AlgorithmES256, nil
+		case elliptic.P384():
+			return cose.AlgorithmES384, nil
+		case elliptic.P521():
+			return cose.AlgorithmES512, nil
+		default:
+			return 0, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		return cose.AlgorithmEdDSA, nil
+	case *rsa.PublicKey:
+		return cose.AlgorithmPS256, nil
+	default:
+		return 0, fmt.Errorf("unsupported key type %T", k)
+	}
+}
+
+// PublicKey returns the public part of the attestation key
+func (a *Attester) PublicKey() crypto.PublicKey {
+	return a.key.Public()
+}
+
+// UEID returns the UEID of the attester
+func (a *Attester) UEID() eat.UEID {
+	return a.ueid
+}
+
+// Claims returns the claims-set that would be signed for the supplied
+// challenge, with fresh iat and cti claims.  The tokens of the nested
+// attesters are signed and embedded in their submods.
+func (a *Attester) Claims(challenge []byte) (*eat.Eat, error) {
+	e := a.template
+
+	var nonce eat.Nonce
+	if err := nonce.Add(challenge); err != nil {
+		return nil, fmt.Errorf("challenge: %w", err)
+	}
+	e.Nonce = &nonce
+
+	ueid := a.ueid
+	e.UEID = &ueid
+
+	iat := eat.NumericDate(a.now())
+	e.IssuedAt = &iat
+
+	cti := make([]byte, ctiSize)
+	if _, err := io.ReadFull(a.rand, cti); err != nil {
+		return nil, fmt.Errorf("generating cti: %w", err)
+	}
+	e.CwtID = &cti
+
+	if len(a.submods) > 0 {
+		submods, err := a.signSubmods(challenge)
+		if err != nil {
+			return nil, err
+		}
+		e.Submods = submods
+	}
+
+	return &e, nil
+}
+
+func (a *Attester) signSubmods(challenge []byte) (*eat.Submods, error) {
+	submods := make(eat.Submods)
+
+	if a.template.Submods != nil {
+		for name, s := range *a.template.Submods {
+			submods[name] = s
+		}
+	}
+
+	for name, sub := range a.submods {
+		token, err := sub.Attest(challenge, FormatCWT)
+		if err != nil {
+			return nil, fmt.Errorf("submod %q: %w", name, err)
+		}
+
+		if err := submods.Add(name, token); err != nil {
+			return nil, fmt.Errorf("submod %q: %w", name, err)
+		}
+	}
+
+	return &submods, nil
+}
+
+// Attest returns a fresh token in the supplied format, signed with the
+// attestation key, whose nonce is the supplied verifier challenge (8 to 64
+// bytes)
+func (a *Attester) Attest(challenge []byte, format Format) ([]byte, error) {
+	e, err := a.Claims(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []eat.SignOption
+	if a.kid != nil {
+		opts = append(opts, eat.WithKeyID(a.kid))
+	}
+
+	switch format {
+	case FormatCWT:
+		return e.Sign(a.rand, a.signer, append(opts, eat.WithCWTTag())...)
+	case FormatJWT:
+		token, err := e.SignJWT(a.rand, a.signer, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(token), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %d", format)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package attester
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/eat"
+)
+
+var testChallenge = []byte{
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+	0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10,
+}
+
+func newTestTemplate() eat.Eat {
+	iss := "https://attester.example"
+	return eat.Eat{CWTClaims: eat.CWTClaims{Issuer: &iss}}
+}
+
+func newTestAttester(t *testing.T, opts ...Option) *Attester {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	a, err := New(key, newTestTemplate(), opts...)
+	require.Nil(t, err)
+
+	return a
+}
+
+func TestAttester_Attest_CWT(t *testing.T) {
+	now := time.Unix(1694498816, 0)
+	a := newTestAttester(t, WithKeyID([]byte("device-1")), WithClock(func() time.Time { return now }))
+
+	token, err := a.Attest(testChallenge, FormatCWT)
+	require.Nil(t, err)
+
+	actual, err := eat.Verify(token, eat.StaticKeyResolver(a.PublicKey()))
+	require.Nil(t, err)
+
+	assert.Equal(t, testChallenge, actual.Eat.Nonce.GetI(0))
+	assert.Equal(t, a.UEID(), *actual.Eat.UEID)
+	assert.Equal(t, "https://attester.example", *actual.Eat.Issuer)
+	assert.Equal(t, now.Unix(), time.Time(*actual.Eat.IssuedAt).Unix())
+	assert.Len(t, *actual.Eat.CwtID, ctiSize)
+
+	another, err := a.Attest(testChallenge, FormatCWT)
+	require.Nil(t, err)
+
+	again, err := eat.Verify(another, eat.StaticKeyResolver(a.PublicKey()))
+	require.Nil(t, err)
+	assert.NotEqual(t, *actual.Eat.CwtID, *again.Eat.CwtID)
+	assert.Equal(t, *actual.Eat.UEID, *again.Eat.UEID)
+}
+
+func TestAttester_Attest_JWT(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+
+	a, err := New(key, newTestTemplate())
+	require.Nil(t, err)
+
+	token, err := a.Attest(testChallenge, FormatJWT)
+	require.Nil(t, err)
+
+	actual, err := eat.VerifyJWT(string(token), eat.StaticKeyResolver(a.PublicKey()))
+	require.Nil(t, err)
+	assert.Equal(t, testChallenge, actual.Eat.Nonce.GetI(0))
+}
+
+func TestAttester_Attest_submods(t *testing.T) {
+	tee := newTestAttester(t)
+	platform := newTestAttester(t, WithSubmod("tee", tee))
+
+	token, err := platform.Attest(testChallenge, FormatJWT)
+	require.Nil(t, err)
+
+	actual, err := eat.VerifyJWT(string(token), eat.StaticKeyResolver(platform.PublicKey()))
+	require.Nil(t, err)
+
+	nested, ok := actual.Eat.Submods.Get("tee").([]byte)
+	require.True(t, ok)
+
+	sub, err := eat.Verify(nested, eat.StaticKeyResolver(tee.PublicKey()))
+	require.Nil(t, err)
+	assert.Equal(t, testChallenge, sub.Eat.Nonce.GetI(0))
+	assert.Equal(t, tee.UEID(), *sub.Eat.UEID)
+}
+
+func TestAttester_NG(t *testing.T) {
+	a := newTestAttester(t)
+
+	_, err := a.Attest([]byte("short"), FormatCWT)
+	assert.ErrorContains(t, err, "challenge: ")
+
+	_, err = a.Attest(testChallenge, Format(2))
+	assert.EqualError(t, err, "unsupported format 2")
+
+	key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	require.Nil(t, err)
+
+	_, err = New(key, newTestTemplate())
+	assert.EqualError(t, err, "unsupported curve P-224")
+
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	_, err = New(key, newTestTemplate(), WithSubmod("tee", nil))
+	assert.EqualError(t, err, `submod "tee": nil attester`)
+}
+
+func TestNewFromPEMFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.Nil(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.Nil(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	a, err := NewFromPEMFile(path, newTestTemplate())
+	require.Nil(t, err)
+	assert.True(t, key.PublicKey.Equal(a.PublicKey()))
+
+	token, err := a.Attest(testChallenge, FormatCWT)
+	require.Nil(t, err)
+
+	_, err = eat.Verify(token, eat.StaticKeyResolver(a.PublicKey()))
+	assert.Nil(t, err)
+
+	require.Nil(t, os.WriteFile(path, []byte("not PEM"), 0600))
+	_, err = NewFromPEMFile(path, newTestTemplate())
+	assert.EqualError(t, err, path+": no PEM block found")
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// encodeBinaryData encodes the supplied bytes as base64url without padding, as
+// required by RFC 9711 for binary-data in JSON
+func encodeBinaryData(v []byte) string {
+	return base64.RawURLEncoding.EncodeToString(v)
+}
+
+// decodeBinaryData decodes the supplied base64 string.  Both the standard and
+// the URL-safe alphabets are accepted, with or without padding.
+func decodeBinaryData(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+
+	if strings.ContainsAny(s, "+/") {
+		return base64.RawStdEncoding.DecodeString(s)
+	}
+
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// binaryData is a byte string that is serialized to JSON as base64url without
+// padding and deserialized leniently (see decodeBinaryData)
+type binaryData []byte
+
+// MarshalJSON encodes the receiver binaryData as a base64url JSON string
+func (b binaryData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeBinaryData(b))
+}
+
+// UnmarshalJSON decodes a base64 JSON string into the receiver binaryData
+func (b *binaryData) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	v, err := decodeBinaryData(s)
+	if err != nil {
+		return err
+	}
+
+	*b = v
+
+	return nil
+}
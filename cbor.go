@@ -3,7 +3,14 @@
 
 package eat
 
-import cbor "github.com/fxamacker/cbor/v2"
+import (
+	"fmt"
+	"io"
+	"math"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/x448/float16"
+)
 
 var (
 	em, emError = initCBOREncMode()
@@ -35,3 +42,110 @@ func init() {
 		panic(dmError)
 	}
 }
+
+// minCBORArgument is the smallest argument that may be encoded with each of the
+// 1, 2, 4 and 8 byte additional information values in preferred serialization
+var minCBORArgument = map[byte]uint64{
+	24: 24,
+	25: 1 << 8,
+	26: 1 << 16,
+	27: 1 << 32,
+}
+
+// checkPreferredSerialization checks that the supplied well-formed CBOR data
+// item uses preferred serialization (RFC 8949, Section 4.1), i.e., that
+// integers, lengths, tag numbers and floating-point values are encoded in
+// their shortest form.  Byte strings are not looked into.
+func checkPreferredSerialization(data []byte) error {
+	off, err := checkPreferredItem(data, 0)
+	if err != nil {
+		return err
+	}
+
+	if off != len(data) {
+		return fmt.Errorf("%d trailing bytes", len(data)-off)
+	}
+
+	return nil
+}
+
+// checkPreferredItem checks the data item starting at off and returns the
+// offset that follows it
+func checkPreferredItem(data []byte, off int) (int, error) {
+	if off >= len(data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	start := off
+	major, ai := data[off]>>5, data[off]&0x1f
+	off++
+
+	var arg uint64
+
+	switch {
+	case ai < 24:
+		arg = uint64(ai)
+	case ai <= 27:
+		n := 1 << (ai - 24)
+		if off+n > len(data) {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		for _, b := range data[off : off+n] {
+			arg = arg<<8 | uint64(b)
+		}
+		off += n
+
+		if major == 7 {
+			if !isShortestFloat(ai, arg) {
+				return 0, fmt.Errorf("non-preferred floating-point encoding at offset %d", start)
+			}
+		} else if arg < minCBORArgument[ai] {
+			return 0, fmt.Errorf("non-preferred argument encoding at offset %d", start)
+		}
+	default:
+		return 0, fmt.Errorf("indefinite-length or reserved encoding at offset %d", start)
+	}
+
+	var items uint64
+
+	switch major {
+	case 2, 3:
+		if arg > uint64(len(data)-off) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return off + int(arg), nil
+	case 4:
+		items = arg
+	case 5:
+		items = 2 * arg
+	case 6:
+		items = 1
+	}
+
+	for ; items > 0; items-- {
+		var err error
+		if off, err = checkPreferredItem(data, off); err != nil {
+			return 0, err
+		}
+	}
+
+	return off, nil
+}
+
+// isShortestFloat reports whether the floating-point value with the supplied
+// additional information and bits could not be encoded in fewer bytes without
+// losing precision.  NaNs are not checked.  Simple values (ai 24) are always
+// in shortest form.
+func isShortestFloat(ai byte, bits uint64) bool {
+	switch ai {
+	case 26:
+		f := math.Float32frombits(uint32(bits))
+		return math.IsNaN(float64(f)) || float16.PrecisionFromfloat32(f) != float16.PrecisionExact
+	case 27:
+		f := math.Float64frombits(bits)
+		return math.IsNaN(f) || float64(float32(f)) != f
+	default:
+		return true
+	}
+}
@@ -0,0 +1,617 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	cbor "github.com/fxamacker/cbor/v2"
+)
+
+/*
+cmw = cmw-record / cmw-collection / cmw-cbor-tag
+
+cmw-record = [
+	type: media-type / coap-content-format
+	value: bytes (CBOR) / base64url-string (JSON)
+	? ind: uint .bits cm-type
+]
+
+cmw-collection = {
+	? "__cmwc_t": ~uri / oid
+	+ &(label: uint / text) => cmw
+}
+
+cmw-cbor-tag = #6.<TN(coap-content-format)>(bytes)
+*/
+
+// Indicator is the CMW indicator, which tells what kind of conceptual message
+// a CMW record carries
+type Indicator uint
+
+// CMW indicator bits
+const (
+	IndicatorReferenceValues Indicator = 1 << iota
+	IndicatorEndorsements
+	IndicatorEvidence
+	IndicatorAttestationResults
+	IndicatorTrustAnchors
+
+	indicatorMask = IndicatorTrustAnchors<<1 - 1
+)
+
+// cmwCollectionType is the label of the optional collection type entry of a
+// CMW collection
+const cmwCollectionType = "__cmwc_t"
+
+// mediaTypeSyntax loosely matches a media type, with optional parameters
+var mediaTypeSyntax = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*(\s*;.*)?$`)
+
+// CMW is a Conceptual Message Wrapper (draft-ietf-rats-msg-wrap).  Exactly one
+// of Record and Collection is set.
+type CMW struct {
+	Record     *CMWRecord
+	Collection *CMWCollection
+}
+
+// CMWRecord is a CMW record, which carries a single conceptual message
+type CMWRecord struct {
+	// MediaType is the type of the message, unless ContentFormat is set
+	MediaType string
+	// ContentFormat is the CoAP content-format of the message, if it is used
+	// in place of the media type
+	ContentFormat *uint16
+	// Value is the message
+	Value []byte
+	// Indicator is optional
+	Indicator Indicator
+}
+
+// CMWCollection is a CMW collection.  Integer labels are converted to their
+// decimal string representation on decoding.
+type CMWCollection struct {
+	// Type is the optional collection type, a URI or an OID
+	Type string
+	// Entries are the wrapped CMWs, by label
+	Entries map[string]CMW
+}
+
+// NewCMWRecord returns a CMW record wrapping the supplied value with the
+// supplied media type
+func NewCMWRecord(mediaType string, value []byte, ind Indicator) (*CMW, error) {
+	r := CMWRecord{MediaType: mediaType, Value: value, Indicator: ind}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CMW{Record: &r}, nil
+}
+
+// NewCMWCollection returns an empty CMW collection of the supplied type, which
+// may be empty
+func NewCMWCollection(typ string) *CMW {
+	return &CMW{Collection: &CMWCollection{Type: typ, Entries: map[string]CMW{}}}
+}
+
+// WrapEAT returns a CMW record wrapping the supplied encoded EAT.  The token
+// is checked against the supplied EAT media type, including its eat_profile
+// parameter, if any.  The token is not verified.
+func WrapEAT(mediaType string, token []byte) (*CMW, error) {
+	if _, err := unwrapEAT(mediaType, token); err != nil {
+		return nil, err
+	}
+
+	return NewCMWRecord(mediaType, token, IndicatorEvidence)
+}
+
+// ToCMW returns a CMW record wrapping the receiver Eat serialized according to
+// the supplied media type (see ToMediaType)
+//
+//nolint:gocritic
+func (e Eat) ToCMW(mediaType string) (*CMW, error) {
+	data, err := e.ToMediaType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCMWRecord(mediaType, data, IndicatorEvidence)
+}
+
+// Add inserts the supplied CMW in the receiver collection under the supplied
+// label
+func (c *CMW) Add(label string, entry CMW) error {
+	if c.Collection == nil {
+		return errors.New("not a CMW collection")
+	}
+
+	if label == "" || label == cmwCollectionType {
+		return fmt.Errorf("invalid CMW collection label %q", label)
+	}
+
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("CMW collection entry %q: %w", label, err)
+	}
+
+	if c.Collection.Entries == nil {
+		c.Collection.Entries = map[string]CMW{}
+	}
+
+	c.Collection.Entries[label] = entry
+
+	return nil
+}
+
+// Get returns the entry of the receiver collection with the supplied label
+func (c CMW) Get(label string) (CMW, bool) {
+	if c.Collection == nil {
+		return CMW{}, false
+	}
+	entry, ok := c.Collection.Entries[label]
+	return entry, ok
+}
+
+// UnwrapEAT decodes the EAT wrapped in the receiver CMW record.  The token is
+// checked against the media type of the record, including its eat_profile
+// parameter, if any.  Signatures are NOT verified (see Parse).
+func (c CMW) UnwrapEAT() (*ParsedToken, error) {
+	if c.Record == nil {
+		return nil, errors.New("not a CMW record")
+	}
+
+	mediaType := c.Record.resolvedType()
+	if mediaType == "" {
+		return nil, fmt.Errorf("unknown content-format %d", *c.Record.ContentFormat)
+	}
+
+	return unwrapEAT(mediaType, c.Record.Value)
+}
+
+func unwrapEAT(mediaType string, token []byte) (*ParsedToken, error) {
+	mt, err := ParseMediaType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Format != mt.Format() {
+		return nil, fmt.Errorf("%s: found %s", mt.Type, t.Format)
+	}
+
+	if err := mt.CheckProfile(t.Eat); err != nil {
+		return nil, fmt.Errorf("%s: %w", mt.Type, err)
+	}
+
+	return t, nil
+}
+
+// resolvedType returns the media type of the receiver record, resolving the
+// content-format if needed.  An empty string is returned for unknown
+// content-formats.
+func (r CMWRecord) resolvedType() string {
+	if r.ContentFormat != nil {
+		return contentFormats[*r.ContentFormat]
+	}
+	return r.MediaType
+}
+
+// Validate checks that the receiver CMW is well-formed
+func (c CMW) Validate() error {
+	switch {
+	case c.Record != nil && c.Collection != nil:
+		return errors.New("both record and collection are set")
+	case c.Record != nil:
+		return c.Record.Validate()
+	case c.Collection != nil:
+		return c.Collection.Validate()
+	default:
+		return errors.New("neither record nor collection is set")
+	}
+}
+
+// Validate checks that the receiver CMWRecord has a well-formed type, a value
+// and a valid indicator
+func (r CMWRecord) Validate() error {
+	if r.ContentFormat == nil && !mediaTypeSyntax.MatchString(r.MediaType) {
+		return fmt.Errorf("invalid media type %q", r.MediaType)
+	}
+
+	if r.ContentFormat != nil && r.MediaType != "" {
+		return errors.New("both media type and content-format are set")
+	}
+
+	if len(r.Value) == 0 {
+		return errors.New("empty value")
+	}
+
+	if r.Indicator&^indicatorMask != 0 {
+		return fmt.Errorf("invalid indicator %#x", uint(r.Indicator))
+	}
+
+	return nil
+}
+
+// Validate checks that the receiver CMWCollection has at least one entry and
+// that all its entries are well-formed
+func (c CMWCollection) Validate() error {
+	if len(c.Entries) == 0 {
+		return errors.New("empty CMW collection")
+	}
+
+	for _, label := range c.labels() {
+		if label == "" || label == cmwCollectionType {
+			return fmt.Errorf("invalid CMW collection label %q", label)
+		}
+		if err := c.Entries[label].Validate(); err != nil {
+			return fmt.Errorf("CMW collection entry %q: %w", label, err)
+		}
+	}
+
+	return nil
+}
+
+func (c CMWCollection) labels() []string {
+	labels := make([]string, 0, len(c.Entries))
+	for label := range c.Entries {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// MarshalCBOR encodes the receiver CMW using the CBOR array form for records
+// and the CBOR map form for collections
+func (c CMW) MarshalCBOR() ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("CBOR encoding of CMW failed: %w", err)
+	}
+
+	if c.Record != nil {
+		return em.Marshal(c.Record.toArray())
+	}
+
+	m := make(map[string]interface{}, len(c.Collection.Entries)+1)
+	if c.Collection.Type != "" {
+		m[cmwCollectionType] = c.Collection.Type
+	}
+	for label, entry := range c.Collection.Entries {
+		m[label] = entry
+	}
+
+	return em.Marshal(m)
+}
+
+func (r CMWRecord) toArray() []interface{} {
+	var a []interface{}
+
+	if r.ContentFormat != nil {
+		a = append(a, *r.ContentFormat)
+	} else {
+		a = append(a, r.MediaType)
+	}
+
+	a = append(a, r.Value)
+
+	if r.Indicator != 0 {
+		a = append(a, uint(r.Indicator))
+	}
+
+	return a
+}
+
+// ToCBORTag encodes the receiver CMW record using the CBOR tag form, which
+// requires a content-format and no indicator
+func (c CMW) ToCBORTag() ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.Record == nil {
+		return nil, errors.New("only CMW records have a CBOR tag form")
+	}
+
+	if c.Record.ContentFormat == nil {
+		return nil, errors.New("the CBOR tag form requires a content-format")
+	}
+
+	if c.Record.Indicator != 0 {
+		return nil, errors.New("the CBOR tag form cannot carry an indicator")
+	}
+
+	return em.Marshal(cbor.Tag{
+		Number:  cmwTagBase + uint64(*c.Record.ContentFormat),
+		Content: c.Record.Value,
+	})
+}
+
+// UnmarshalCBOR decodes a CMW in either of the CBOR array, CBOR tag or CBOR
+// map forms into the receiver CMW
+func (c *CMW) UnmarshalCBOR(data []byte) error {
+	*c = CMW{}
+
+	switch {
+	case isCBORArray(data):
+		var rec []cbor.RawMessage
+		if err := dm.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("malformed CMW record: %w", err)
+		}
+		r, err := decodeCBORCMWRecord(rec)
+		if err != nil {
+			return fmt.Errorf("malformed CMW record: %w", err)
+		}
+		c.Record = r
+	case isCBORTag(data):
+		r, err := decodeCMWTag(data)
+		if err != nil {
+			return err
+		}
+		c.Record = r
+	case isCBORMap(data):
+		var m map[interface{}]cbor.RawMessage
+		if err := dm.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("malformed CMW collection: %w", err)
+		}
+		col, err := decodeCBORCMWCollection(m)
+		if err != nil {
+			return err
+		}
+		c.Collection = col
+	default:
+		return errors.New("CMW must be a CBOR array, tag or map")
+	}
+
+	return c.Validate()
+}
+
+// decodeCBORCMWRecord decodes a CBOR CMW record:
+// [ type: uint / tstr, value: bstr, ? ind: uint ]
+func decodeCBORCMWRecord(rec []cbor.RawMessage) (*CMWRecord, error) {
+	if len(rec) != 2 && len(rec) != 3 {
+		return nil, fmt.Errorf("expecting 2 or 3 items, found %d", len(rec))
+	}
+
+	var r CMWRecord
+
+	switch {
+	case isCBORUint(rec[0]):
+		var cf uint16
+		if err := dm.Unmarshal(rec[0], &cf); err != nil {
+			return nil, fmt.Errorf("invalid content-format: %w", err)
+		}
+		r.ContentFormat = &cf
+	case isCBORTextString(rec[0]):
+		if err := dm.Unmarshal(rec[0], &r.MediaType); err != nil {
+			return nil, fmt.Errorf("invalid media type: %w", err)
+		}
+	default:
+		return nil, errors.New("type must be a media type or a content-format")
+	}
+
+	if !isCBORByteString(rec[1]) {
+		return nil, errors.New("value must be a byte string")
+	}
+	if err := dm.Unmarshal(rec[1], &r.Value); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	if len(rec) == 3 {
+		if !isCBORUint(rec[2]) {
+			return nil, errors.New("indicator must be an unsigned integer")
+		}
+		if err := dm.Unmarshal(rec[2], &r.Indicator); err != nil {
+			return nil, fmt.Errorf("invalid indicator: %w", err)
+		}
+	}
+
+	return &r, nil
+}
+
+func decodeCMWTag(data []byte) (*CMWRecord, error) {
+	var tag cbor.RawTag
+	if err := dm.Unmarshal(data, &tag); err != nil {
+		return nil, fmt.Errorf("malformed CMW tag: %w", err)
+	}
+
+	if tag.Number < cmwTagBase || tag.Number > cmwTagMax {
+		return nil, fmt.Errorf("CBOR tag %d is not a CMW tag", tag.Number)
+	}
+
+	// the tagged value is normally wrapped in a byte string, but accept the
+	// bare CBOR data item too
+	value := []byte(tag.Content)
+	if isCBORByteString(tag.Content) {
+		if err := dm.Unmarshal(tag.Content, &value); err != nil {
+			return nil, fmt.Errorf("invalid CMW value: %w", err)
+		}
+	}
+
+	cf := uint16(tag.Number - cmwTagBase)
+
+	return &CMWRecord{ContentFormat: &cf, Value: value}, nil
+}
+
+func decodeCBORCMWCollection(m map[interface{}]cbor.RawMessage) (*CMWCollection, error) {
+	col := CMWCollection{Entries: make(map[string]CMW, len(m))}
+
+	for k, v := range m {
+		if k == cmwCollectionType {
+			if err := dm.Unmarshal(v, &col.Type); err != nil {
+				return nil, fmt.Errorf("invalid CMW collection type: %w", err)
+			}
+			continue
+		}
+
+		var label string
+		switch l := k.(type) {
+		case string:
+			label = l
+		case uint64:
+			label = strconv.FormatUint(l, 10)
+		default:
+			return nil, fmt.Errorf("invalid CMW collection label type %T", k)
+		}
+
+		if _, dup := col.Entries[label]; dup {
+			return nil, fmt.Errorf("duplicate CMW collection label %q", label)
+		}
+
+		var entry CMW
+		if err := entry.UnmarshalCBOR(v); err != nil {
+			return nil, fmt.Errorf("CMW collection entry %q: %w", label, err)
+		}
+
+		col.Entries[label] = entry
+	}
+
+	return &col, nil
+}
+
+// MarshalJSON encodes the receiver CMW using the JSON array form for records
+// and the JSON object form for collections
+func (c CMW) MarshalJSON() ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("JSON encoding of CMW failed: %w", err)
+	}
+
+	if c.Record != nil {
+		a := c.Record.toArray()
+		a[1] = encodeBinaryData(c.Record.Value)
+		return json.Marshal(a)
+	}
+
+	m := make(map[string]interface{}, len(c.Collection.Entries)+1)
+	if c.Collection.Type != "" {
+		m[cmwCollectionType] = c.Collection.Type
+	}
+	for label, entry := range c.Collection.Entries {
+		m[label] = entry
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes a CMW in either of the JSON array or JSON object forms
+// into the receiver CMW
+func (c *CMW) UnmarshalJSON(data []byte) error {
+	*c = CMW{}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch v.(type) {
+	case []interface{}:
+		var rec []json.RawMessage
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("malformed CMW record: %w", err)
+		}
+		r, err := decodeJSONCMWRecord(rec)
+		if err != nil {
+			return fmt.Errorf("malformed CMW record: %w", err)
+		}
+		c.Record = r
+	case map[string]interface{}:
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("malformed CMW collection: %w", err)
+		}
+		col, err := decodeJSONCMWCollection(m)
+		if err != nil {
+			return err
+		}
+		c.Collection = col
+	default:
+		return errors.New("CMW must be a JSON array or object")
+	}
+
+	return c.Validate()
+}
+
+// decodeJSONCMWRecord decodes a JSON CMW record:
+// [ type: string / uint, value: base64url-string, ? ind: uint ]
+func decodeJSONCMWRecord(rec []json.RawMessage) (*CMWRecord, error) {
+	if len(rec) != 2 && len(rec) != 3 {
+		return nil, fmt.Errorf("expecting 2 or 3 items, found %d", len(rec))
+	}
+
+	var r CMWRecord
+
+	if err := json.Unmarshal(rec[0], &r.MediaType); err != nil {
+		var cf uint16
+		if err := json.Unmarshal(rec[0], &cf); err != nil {
+			return nil, errors.New("type must be a media type or a content-format")
+		}
+		r.ContentFormat = &cf
+	}
+
+	var v binaryData
+	if err := json.Unmarshal(rec[1], &v); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	r.Value = v
+
+	if len(rec) == 3 {
+		if err := json.Unmarshal(rec[2], &r.Indicator); err != nil {
+			return nil, fmt.Errorf("invalid indicator: %w", err)
+		}
+	}
+
+	return &r, nil
+}
+
+func decodeJSONCMWCollection(m map[string]json.RawMessage) (*CMWCollection, error) {
+	col := CMWCollection{Entries: make(map[string]CMW, len(m))}
+
+	for label, v := range m {
+		if label == cmwCollectionType {
+			if err := json.Unmarshal(v, &col.Type); err != nil {
+				return nil, fmt.Errorf("invalid CMW collection type: %w", err)
+			}
+			continue
+		}
+
+		var entry CMW
+		if err := entry.UnmarshalJSON(v); err != nil {
+			return nil, fmt.Errorf("CMW collection entry %q: %w", label, err)
+		}
+
+		col.Entries[label] = entry
+	}
+
+	return &col, nil
+}
+
+// parse converts the receiver CMW into a ParsedToken, decoding the wrapped
+// EATs.  The media type is that of the serialization of collections.
+func (c CMW) parse(collectionMediaType string) (*ParsedToken, error) {
+	if c.Record != nil {
+		return parseCMWValue(c.Record.resolvedType(), c.Record.Value)
+	}
+
+	t := ParsedToken{
+		Format:     FormatCMWCollection,
+		MediaType:  collectionMediaType,
+		Collection: make(map[string]*ParsedToken, len(c.Collection.Entries)),
+	}
+
+	for _, label := range c.Collection.labels() {
+		entry, err := c.Collection.Entries[label].parse(collectionMediaType)
+		if err != nil {
+			return nil, fmt.Errorf("CMW collection entry %q: %w", label, err)
+		}
+		t.Collection[label] = entry
+	}
+
+	return &t, nil
+}
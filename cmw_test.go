@@ -0,0 +1,149 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func TestCMW_Record_RoundTrip(t *testing.T) {
+	e := newTestClaims(t)
+
+	c, err := e.ToCMW(MediaTypeUCCS)
+	require.Nil(t, err)
+	assert.Equal(t, IndicatorEvidence, c.Record.Indicator)
+
+	data, err := c.MarshalCBOR()
+	require.Nil(t, err)
+
+	var actual CMW
+	require.Nil(t, actual.UnmarshalCBOR(data))
+	assert.Equal(t, *c, actual)
+
+	data, err = json.Marshal(c)
+	require.Nil(t, err)
+
+	actual = CMW{}
+	require.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, *c, actual)
+
+	parsed, err := actual.UnwrapEAT()
+	require.Nil(t, err)
+	assert.Equal(t, FormatUCCS, parsed.Format)
+	assert.Equal(t, e.UEID, parsed.Eat.UEID)
+}
+
+func TestCMW_Tag_RoundTrip(t *testing.T) {
+	e := newTestClaims(t)
+	cwt := signTestToken(t, e, cose.AlgorithmES256, nil, true)
+
+	cf := uint16(263)
+	c := CMW{Record: &CMWRecord{ContentFormat: &cf, Value: cwt}}
+
+	data, err := c.ToCBORTag()
+	require.Nil(t, err)
+
+	var actual CMW
+	require.Nil(t, actual.UnmarshalCBOR(data))
+	assert.Equal(t, c, actual)
+
+	parsed, err := actual.UnwrapEAT()
+	require.Nil(t, err)
+	assert.Equal(t, FormatCWT, parsed.Format)
+	assert.Equal(t, e.Nonce, parsed.Eat.Nonce)
+
+	c.Record.Indicator = IndicatorEvidence
+	_, err = c.ToCBORTag()
+	assert.EqualError(t, err, "the CBOR tag form cannot carry an indicator")
+
+	c.Record.ContentFormat = nil
+	c.Record.MediaType = MediaTypeCWT
+	_, err = c.ToCBORTag()
+	assert.EqualError(t, err, "the CBOR tag form requires a content-format")
+}
+
+func TestCMW_Collection_RoundTrip(t *testing.T) {
+	e := newTestClaims(t)
+	cwt := signTestToken(t, e, cose.AlgorithmES256, nil, true)
+
+	eat, err := WrapEAT(MediaTypeCWT, cwt)
+	require.Nil(t, err)
+
+	other, err := NewCMWRecord("application/vnd.example.rv", []byte{0x00, 0x01}, IndicatorReferenceValues)
+	require.Nil(t, err)
+
+	nested := NewCMWCollection("")
+	require.Nil(t, nested.Add("other", *other))
+
+	c := NewCMWCollection("tag:example.com,2025:composite")
+	require.Nil(t, c.Add("eat", *eat))
+	require.Nil(t, c.Add("nested", *nested))
+
+	for _, codec := range []struct {
+		name      string
+		marshal   func(CMW) ([]byte, error)
+		unmarshal func([]byte, *CMW) error
+		mediaType string
+	}{
+		{"CBOR", CMW.MarshalCBOR, func(d []byte, c *CMW) error { return c.UnmarshalCBOR(d) }, MediaTypeCMWCBOR},
+		{"JSON", CMW.MarshalJSON, func(d []byte, c *CMW) error { return c.UnmarshalJSON(d) }, MediaTypeCMWJSON},
+	} {
+		data, err := codec.marshal(*c)
+		require.Nil(t, err, codec.name)
+
+		var actual CMW
+		require.Nil(t, codec.unmarshal(data, &actual), codec.name)
+		assert.Equal(t, *c, actual, codec.name)
+
+		parsed, err := Parse(data)
+		require.Nil(t, err, codec.name)
+		assert.Equal(t, FormatCMWCollection, parsed.Format, codec.name)
+		assert.Equal(t, codec.mediaType, parsed.MediaType, codec.name)
+		assert.Equal(t, e.Nonce, parsed.Collection["eat"].Eat.Nonce, codec.name)
+		assert.Nil(t, parsed.Collection["nested"].Collection["other"].Eat, codec.name)
+	}
+
+	entry, ok := c.Get("eat")
+	require.True(t, ok)
+	assert.Equal(t, MediaTypeCWT, entry.Record.MediaType)
+}
+
+func TestCMW_NG(t *testing.T) {
+	e := newTestClaims(t)
+	claims, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	_, err = WrapEAT(MediaTypeCWT, claims)
+	assert.EqualError(t, err, "application/eat+cwt: found UCCS")
+
+	_, err = WrapEAT(MediaTypeUCCS+`; eat_profile="urn:example"`, claims)
+	assert.EqualError(t, err, `application/eat-ucs+cbor: missing eat_profile claim, expecting "urn:example"`)
+
+	_, err = NewCMWRecord("not a media type", claims, 0)
+	assert.EqualError(t, err, `invalid media type "not a media type"`)
+
+	_, err = NewCMWRecord(MediaTypeUCCS, claims, 0x20)
+	assert.EqualError(t, err, "invalid indicator 0x20")
+
+	c := NewCMWCollection("")
+	_, err = c.MarshalCBOR()
+	assert.EqualError(t, err, "CBOR encoding of CMW failed: empty CMW collection")
+	assert.EqualError(t, c.Add(cmwCollectionType, CMW{}), `invalid CMW collection label "__cmwc_t"`)
+
+	rec, err := NewCMWRecord(MediaTypeUCCS, claims, 0)
+	require.Nil(t, err)
+	assert.EqualError(t, rec.Add("x", *rec), "not a CMW collection")
+
+	var actual CMW
+	assert.EqualError(t, actual.UnmarshalCBOR([]byte{0x40}), "CMW must be a CBOR array, tag or map")
+	assert.EqualError(t, actual.UnmarshalJSON([]byte(`["application/eat+cwt", "AA", "x"]`)),
+		"malformed CMW record: invalid indicator: json: cannot unmarshal string into Go value of type eat.Indicator")
+	assert.EqualError(t, actual.UnmarshalJSON([]byte(`{"a": ["application/eat+cwt"]}`)),
+		`CMW collection entry "a": malformed CMW record: expecting 2 or 3 items, found 1`)
+}
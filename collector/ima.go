@@ -0,0 +1,347 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+)
+
+// IMA templates supported by the parsers
+const (
+	IMATemplateNG  = "ima-ng"
+	IMATemplateSig = "ima-sig"
+)
+
+// IMATemplateHashSize is the size of the template hash in the SHA-1 binary
+// runtime measurement list (binary_runtime_measurements)
+const IMATemplateHashSize = 20
+
+// maxIMAFieldSize bounds the size of the fields of a binary measurement list
+// entry
+const maxIMAFieldSize = 1 << 16
+
+// imaHashAlgorithms maps the kernel names of the IMA file hash algorithms to
+// their Named Information identifiers.  Algorithms that are not in the Named
+// Information registry (e.g., SHA-1) cannot be reported.
+var imaHashAlgorithms = map[string]uint64{
+	"sha256":   swid.Sha256,
+	"sha384":   swid.Sha384,
+	"sha512":   swid.Sha512,
+	"sha3-224": swid.Sha3_224,
+	"sha3-256": swid.Sha3_256,
+	"sha3-384": swid.Sha3_384,
+	"sha3-512": swid.Sha3_512,
+}
+
+// IMAEntry is an entry of the IMA runtime measurement list using the ima-ng or
+// ima-sig template
+type IMAEntry struct {
+	// PCR is the PCR the entry was extended into, normally 10
+	PCR uint32
+	// TemplateHash is the digest of the template data
+	TemplateHash []byte
+	// Template is the template name, IMATemplateNG or IMATemplateSig
+	Template string
+	// HashAlgorithm is the kernel name of the file hash algorithm, e.g.
+	// "sha256"
+	HashAlgorithm string
+	// Digest is the file digest
+	Digest []byte
+	// Path is the path of the measured file, or "boot_aggregate"
+	Path string
+	// Signature is the file signature (the security.ima extended attribute) of
+	// an ima-sig entry, if any
+	Signature []byte
+}
+
+// ParseIMAASCII parses an ASCII runtime measurement list, as found in
+// /sys/kernel/security/ima/ascii_runtime_measurements.  Each line is
+//
+//	<pcr> <template-hash> <template> <hash-algorithm>:<digest> <path>
+//
+// for ima-ng entries, and
+//
+//	<pcr> <template-hash> <template> <hash-algorithm>:<digest> <path> <signature>
+//
+// for ima-sig entries, where the signature is empty if the file is not signed.
+// Fields are separated by single spaces, and the path may contain spaces.
+func ParseIMAASCII(r io.Reader) ([]IMAEntry, error) {
+	var entries []IMAEntry
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxIMAFieldSize)
+
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		e, err := parseIMAASCIILine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		entries = append(entries, *e)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func parseIMAASCIILine(line string) (*IMAEntry, error) {
+	fields := strings.SplitN(line, " ", 5)
+	if len(fields) < 5 {
+		return nil, fmt.Errorf("expecting at least 5 fields, found %d", len(fields))
+	}
+
+	pcr, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("PCR: %w", err)
+	}
+
+	e := IMAEntry{PCR: uint32(pcr), Template: fields[2]}
+
+	if e.TemplateHash, err = hex.DecodeString(fields[1]); err != nil {
+		return nil, fmt.Errorf("template hash: %w", err)
+	}
+
+	alg, digest, ok := strings.Cut(fields[3], ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed file digest %q", fields[3])
+	}
+
+	e.HashAlgorithm = alg
+	if e.Digest, err = hex.DecodeString(digest); err != nil {
+		return nil, fmt.Errorf("file digest: %w", err)
+	}
+
+	switch e.Template {
+	case IMATemplateNG:
+		e.Path = fields[4]
+	case IMATemplateSig:
+		// the signature field is always present, and follows the last space
+		i := strings.LastIndexByte(fields[4], ' ')
+		if i < 0 {
+			return nil, errors.New("expecting 6 fields, found 5")
+		}
+
+		e.Path = fields[4][:i]
+
+		if sig := fields[4][i+1:]; sig != "" {
+			if e.Signature, err = hex.DecodeString(sig); err != nil {
+				return nil, fmt.Errorf("signature: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported template %q", e.Template)
+	}
+
+	return &e, nil
+}
+
+// ParseIMABinary parses a binary runtime measurement list in little-endian
+// byte order, as found in /sys/kernel/security/ima/binary_runtime_measurements
+// on little-endian hosts.  templateHashSize is the size of the template hash of
+// the list, i.e. IMATemplateHashSize, or the digest size of the bank for the
+// binary_runtime_measurements_<alg> lists.
+func ParseIMABinary(r io.Reader, templateHashSize int) ([]IMAEntry, error) {
+	if templateHashSize <= 0 {
+		return nil, fmt.Errorf("invalid template hash size %d", templateHashSize)
+	}
+
+	var entries []IMAEntry
+
+	for n := 0; ; n++ {
+		e, err := parseIMABinaryEntry(r, templateHashSize)
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("entry at index %d: %w", n, err)
+		}
+
+		entries = append(entries, *e)
+	}
+}
+
+// parseIMABinaryEntry returns io.EOF only if there are no more entries
+func parseIMABinaryEntry(r io.Reader, templateHashSize int) (*IMAEntry, error) {
+	var e IMAEntry
+
+	if err := binary.Read(r, binary.LittleEndian, &e.PCR); err != nil {
+		return nil, err
+	}
+
+	e.TemplateHash = make([]byte, templateHashSize)
+	if _, err := io.ReadFull(r, e.TemplateHash); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	name, err := readIMAField(r)
+	if err != nil {
+		return nil, fmt.Errorf("template name: %w", err)
+	}
+	e.Template = string(name)
+
+	data, err := readIMAField(r)
+	if err != nil {
+		return nil, fmt.Errorf("template data: %w", err)
+	}
+
+	if err := e.parseTemplateData(data); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+/*
+ima-ng template data: d-ng | n-ng
+ima-sig template data: d-ng | n-ng | sig
+
+each field is prefixed by its 32-bit length, and
+  d-ng = <hash-algorithm> ":" NUL <digest>
+  n-ng = <path> NUL
+*/
+
+func (e *IMAEntry) parseTemplateData(data []byte) error {
+	var nFields int
+
+	switch e.Template {
+	case IMATemplateNG:
+		nFields = 2
+	case IMATemplateSig:
+		nFields = 3
+	default:
+		return fmt.Errorf("unsupported template %q", e.Template)
+	}
+
+	r := bytes.NewReader(data)
+	fields := make([][]byte, nFields)
+
+	for i := range fields {
+		f, err := readIMAField(r)
+		if err != nil {
+			return fmt.Errorf("template field %d: %w", i, err)
+		}
+		fields[i] = f
+	}
+
+	if r.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes in template data", r.Len())
+	}
+
+	alg, digest, ok := bytes.Cut(fields[0], []byte(":\x00"))
+	if !ok {
+		return errors.New("malformed d-ng field")
+	}
+
+	e.HashAlgorithm = string(alg)
+	e.Digest = digest
+	e.Path = string(bytes.TrimSuffix(fields[1], []byte{0}))
+
+	if nFields == 3 && len(fields[2]) > 0 {
+		e.Signature = fields[2]
+	}
+
+	return nil
+}
+
+func readIMAField(r io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if size > maxIMAFieldSize {
+		return nil, fmt.Errorf("field size %d exceeds %d", size, maxIMAFieldSize)
+	}
+
+	f := make([]byte, size)
+	if _, err := io.ReadFull(r, f); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return f, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// MeasuredComponent returns the receiver entry as a measured component named
+// after the file path, with the signature, if any, as signer
+//
+//nolint:gocritic
+func (e IMAEntry) MeasuredComponent() (*eat.MeasuredComponent, error) {
+	alg, ok := imaHashAlgorithms[e.HashAlgorithm]
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported hash algorithm %q", e.Path, e.HashAlgorithm)
+	}
+
+	d := eat.Digest{Alg: alg, Value: e.Digest}
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Path, err)
+	}
+
+	mc := eat.MeasuredComponent{
+		Id:          eat.ComponentID{Name: e.Path},
+		Measurement: &d,
+	}
+
+	if len(e.Signature) > 0 {
+		eat.WithSigners(e.Signature)(&mc)
+	}
+
+	return &mc, nil
+}
+
+// IMAMeasurements returns the supplied entries as measurements of type
+// measured-component, for use in the Measurements claim.  Entries whose file
+// hash algorithm cannot be reported (see IMAEntry.MeasuredComponent), such as
+// the SHA-1 entries of kernels using the default IMA hash, are not converted:
+// they are returned as skipped, in list order.
+func IMAMeasurements(entries []IMAEntry) ([]eat.Measurement, []IMAEntry, error) {
+	var skipped []IMAEntry
+
+	measurements := make([]eat.Measurement, 0, len(entries))
+
+	for i, e := range entries {
+		if _, ok := imaHashAlgorithms[e.HashAlgorithm]; !ok {
+			skipped = append(skipped, e)
+			continue
+		}
+
+		mc, err := e.MeasuredComponent()
+		if err != nil {
+			return nil, nil, fmt.Errorf("IMA entry at index %d: %w", i, err)
+		}
+
+		m, err := mc.ToMeasurement(measuredComponentContentFormat)
+		if err != nil {
+			return nil, nil, fmt.Errorf("IMA entry at index %d: %w", i, err)
+		}
+
+		measurements = append(measurements, *m)
+	}
+
+	return measurements, skipped, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+)
+
+// the testdata measurement lists record the same entries, and each file digest
+// is the SHA-256 of the contents below
+var testIMAContents = map[string]string{
+	"boot_aggregate":           "boot aggregate",
+	"/usr/lib/systemd/systemd": "systemd",
+	"/usr/bin/bash":            "bash",
+	"/etc/ssh/sshd_config":     "sshd config",
+	"/etc/my app.conf":         "my app",
+}
+
+func readTestData(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.Nil(t, err)
+	return data
+}
+
+func TestParseIMA_ASCII_Binary(t *testing.T) {
+	ascii, err := ParseIMAASCII(bytes.NewReader(readTestData(t, "ascii_runtime_measurements")))
+	require.Nil(t, err)
+
+	bin, err := ParseIMABinary(bytes.NewReader(readTestData(t, "binary_runtime_measurements")), IMATemplateHashSize)
+	require.Nil(t, err)
+
+	require.Len(t, ascii, 5)
+	assert.Equal(t, ascii, bin)
+
+	assert.Equal(t, uint32(10), ascii[0].PCR)
+	assert.Equal(t, IMATemplateNG, ascii[0].Template)
+	assert.Equal(t, "boot_aggregate", ascii[0].Path)
+
+	assert.Equal(t, IMATemplateSig, ascii[2].Template)
+	assert.Equal(t, "/usr/bin/bash", ascii[2].Path)
+	assert.Equal(t, []byte{0x03, 0x02, 0x04}, ascii[2].Signature[:3])
+
+	assert.Equal(t, IMATemplateSig, ascii[3].Template)
+	assert.Nil(t, ascii[3].Signature)
+
+	assert.Equal(t, "/etc/my app.conf", ascii[4].Path)
+}
+
+func TestIMAMeasurements(t *testing.T) {
+	entries, err := ParseIMAASCII(bytes.NewReader(readTestData(t, "ascii_runtime_measurements")))
+	require.Nil(t, err)
+
+	measurements, skipped, err := IMAMeasurements(entries)
+	require.Nil(t, err)
+	require.Len(t, measurements, len(entries))
+	assert.Nil(t, skipped)
+
+	for i, m := range measurements {
+		assert.Equal(t, measuredComponentContentFormat, m.Type)
+
+		var mc eat.MeasuredComponent
+		require.Nil(t, cbor.Unmarshal(m.Format, &mc))
+
+		assert.Equal(t, entries[i].Path, mc.Id.Name)
+		assert.Equal(t, swid.Sha256, mc.Measurement.Alg)
+		assert.Nil(t, mc.Verify([]byte(testIMAContents[mc.Id.Name])))
+
+		if entries[i].Signature != nil {
+			assert.Equal(t, [][]byte{entries[i].Signature}, *mc.Signers)
+		} else {
+			assert.Nil(t, mc.Signers)
+		}
+	}
+
+	entries[0].HashAlgorithm = "sha1"
+	measurements, skipped, err = IMAMeasurements(entries)
+	require.Nil(t, err)
+	assert.Len(t, measurements, len(entries)-1)
+	assert.Equal(t, []IMAEntry{entries[0]}, skipped)
+
+	entries[1].Digest = entries[1].Digest[:4]
+	_, _, err = IMAMeasurements(entries)
+	assert.EqualError(t, err, "IMA entry at index 1: /usr/lib/systemd/systemd: "+
+		"length mismatch for hash algorithm sha-256: want 32 bytes, got 4")
+}
+
+func TestParseIMAASCII_paths(t *testing.T) {
+	for _, tv := range []struct {
+		line      string
+		path      string
+		signature []byte
+	}{
+		{"10 00 ima-ng sha256:00 /tmp/a cafe", "/tmp/a cafe", nil},
+		{"10 00 ima-sig sha256:00 /tmp/a cafe ", "/tmp/a cafe", nil},
+		{"10 00 ima-sig sha256:00 /tmp/a cafe 0302", "/tmp/a cafe", []byte{0x03, 0x02}},
+	} {
+		entries, err := ParseIMAASCII(strings.NewReader(tv.line))
+		require.Nil(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, tv.path, entries[0].Path)
+		assert.Equal(t, tv.signature, entries[0].Signature)
+	}
+}
+
+func TestParseIMAASCII_NG(t *testing.T) {
+	for _, tv := range []struct {
+		line     string
+		expected string
+	}{
+		{"10 00 ima-ng sha256:00", "line 1: expecting at least 5 fields, found 4"},
+		{"x 00 ima-ng sha256:00 /bin/sh", `line 1: PCR: strconv.ParseUint: parsing "x": invalid syntax`},
+		{"10 00 ima sha256:00 /bin/sh", `line 1: unsupported template "ima"`},
+		{"10 00 ima-ng 00 /bin/sh", `line 1: malformed file digest "00"`},
+		{"10 00 ima-sig sha256:00 /bin/sh", "line 1: expecting 6 fields, found 5"},
+		{"10 00 ima-sig sha256:00 /bin/sh xyz", "line 1: signature: encoding/hex: invalid byte: U+0078 'x'"},
+	} {
+		_, err := ParseIMAASCII(strings.NewReader(tv.line))
+		assert.EqualError(t, err, tv.expected)
+	}
+}
+
+func TestParseIMABinary_truncated(t *testing.T) {
+	data := readTestData(t, "binary_runtime_measurements")
+
+	_, err := ParseIMABinary(bytes.NewReader(data[:len(data)-1]), IMATemplateHashSize)
+	assert.EqualError(t, err, "entry at index 4: template data: unexpected EOF")
+
+	_, err = ParseIMABinary(bytes.NewReader(data), 0)
+	assert.EqualError(t, err, "invalid template hash size 0")
+}
+
+func TestLinux_Collect_IMA(t *testing.T) {
+	root := newTestRoot(t)
+	sha1Entry := "10 00 ima-ng sha1:0000000000000000000000000000000000000000 /usr/bin/ls\n"
+	writeRootFile(t, root, DefaultLinuxPaths.IMALog, sha1Entry+string(readTestData(t, "ascii_runtime_measurements")))
+
+	var e eat.Eat
+	require.Nil(t, Linux{Root: root, Files: []string{"/boot/vmlinuz"}, IMA: true}.Collect(&e))
+	assert.Len(t, *e.Measurements, 6)
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package collector populates Entity Attestation Tokens with claims gathered from
+the host they are produced on.
+*/
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+)
+
+// LinuxPaths are the locations of the host information read by the Linux
+// collector.  Relative paths are not allowed.
+type LinuxPaths struct {
+	// Uptime is the file with the uptime in seconds
+	Uptime string
+	// BootID is the file with the random UUID generated at each boot
+	BootID string
+	// OSRelease are the os-release files, in order of preference
+	OSRelease []string
+	// KernelRelease is the file with the kernel release
+	KernelRelease string
+	// MachineID is the file with the machine ID
+	MachineID string
+	// IMALog is the ASCII IMA runtime measurement list
+	IMALog string
+}
+
+// DefaultLinuxPaths are the standard locations of the host information
+var DefaultLinuxPaths = LinuxPaths{
+	Uptime:        "/proc/uptime",
+	BootID:        "/proc/sys/kernel/random/boot_id",
+	OSRelease:     []string{"/etc/os-release", "/usr/lib/os-release"},
+	KernelRelease: "/proc/sys/kernel/osrelease",
+	MachineID:     "/etc/machine-id",
+	IMALog:        "/sys/kernel/security/ima/ascii_runtime_measurements",
+}
+
+// measuredComponentContentFormat is the CoAP content-format of the measured
+// components in Measurements.  No value has been allocated for
+// application/measured-component+cbor yet (TBD1 in
+// draft-ietf-rats-eat-measured-component), so one from the experimental range
+// is used until it is.
+const measuredComponentContentFormat = 65000
+
+// defaultUEIDKey is the key used to derive the UEID from the machine ID when
+// Linux.UEIDKey is not set
+const defaultUEIDKey = "github.com/veraison/eat/collector"
+
+// Linux collects claims from the local Linux host:
+//
+//   - uptime, from /proc/uptime
+//   - bootseed, from the boot ID
+//   - swname and swversion, from the NAME and VERSION_ID of os-release, or
+//     "Linux" and the kernel release if they are not available
+//   - ueid, a RAND UEID derived from the machine ID (see UEIDKey)
+//   - measurements, the digests of the configured files as measured components,
+//     followed by the IMA runtime measurement list if enabled
+//
+// Information that is not available on the host is skipped.
+type Linux struct {
+	// Root is the directory that all paths are relative to, e.g. a fake root
+	// filesystem in tests.  If empty, it is "/".
+	Root string
+	// Paths are the locations of the host information.  Empty fields take the
+	// value from DefaultLinuxPaths.
+	Paths LinuxPaths
+	// Files are the absolute paths of the files to measure.  They must exist.
+	Files []string
+	// HashAlgorithm is the Named Information hash algorithm identifier used to
+	// measure Files.  If zero, SHA-256 is used.
+	HashAlgorithm uint64
+	// UEIDKey is the HMAC-SHA256 key used to derive the UEID from the machine
+	// ID, so that the machine ID itself is not disclosed.  The UEID is stable
+	// across boots.  If nil, a fixed package key is used.
+	UEIDKey []byte
+	// IMA adds the entries of the IMA runtime measurement list (see
+	// ParseIMAASCII) to Measurements.  The list must exist.  Entries that
+	// IMAMeasurements skips, e.g. SHA-1 ones, are left out.
+	IMA bool
+}
+
+// Collect sets the claims gathered from the host in the supplied Eat,
+// overwriting any existing value.  Measurements are appended.
+//
+//nolint:gocritic
+func (c Linux) Collect(e *eat.Eat) error {
+	if e == nil {
+		return errors.New("nil Eat")
+	}
+
+	p := c.paths()
+
+	collectors := []func(*eat.Eat, LinuxPaths) error{
+		c.collectUptime,
+		c.collectBootSeed,
+		c.collectSoftware,
+		c.collectUEID,
+		c.collectMeasurements,
+		c.collectIMA,
+	}
+
+	for _, collect := range collectors {
+		if err := collect(e, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocritic
+func (c Linux) paths() LinuxPaths {
+	p := c.Paths
+	d := DefaultLinuxPaths
+
+	if p.Uptime == "" {
+		p.Uptime = d.Uptime
+	}
+	if p.BootID == "" {
+		p.BootID = d.BootID
+	}
+	if len(p.OSRelease) == 0 {
+		p.OSRelease = d.OSRelease
+	}
+	if p.KernelRelease == "" {
+		p.KernelRelease = d.KernelRelease
+	}
+	if p.MachineID == "" {
+		p.MachineID = d.MachineID
+	}
+	if p.IMALog == "" {
+		p.IMALog = d.IMALog
+	}
+
+	return p
+}
+
+// path returns the location of the supplied absolute path under Root
+//
+//nolint:gocritic
+func (c Linux) path(p string) (string, error) {
+	if !filepath.IsAbs(p) {
+		return "", fmt.Errorf("path %q is not absolute", p)
+	}
+
+	root := c.Root
+	if root == "" {
+		root = "/"
+	}
+
+	return filepath.Join(root, p), nil
+}
+
+// readFile returns the trimmed contents of the supplied file, or nil if it does
+// not exist
+//
+//nolint:gocritic
+func (c Linux) readFile(p string) ([]byte, error) {
+	path, err := c.path(p)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(data), nil
+}
+
+//nolint:gocritic
+func (c Linux) collectUptime(e *eat.Eat, p LinuxPaths) error {
+	data, err := c.readFile(p.Uptime)
+	if err != nil || data == nil {
+		return err
+	}
+
+	// e.g., "350735.47 234388.90": uptime and idle time in seconds
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: empty", p.Uptime)
+	}
+
+	seconds, _, _ := strings.Cut(fields[0], ".")
+
+	uptime, err := strconv.ParseUint(seconds, 10, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.Uptime, err)
+	}
+
+	u := uint(uptime)
+	e.Uptime = &u
+
+	return nil
+}
+
+//nolint:gocritic
+func (c Linux) collectBootSeed(e *eat.Eat, p LinuxPaths) error {
+	data, err := c.readFile(p.BootID)
+	if err != nil || data == nil {
+		return err
+	}
+
+	// e.g., "6b8f7e3c-2d4a-4c8e-9f1b-0a5d3e7c9b21"
+	seed, err := hex.DecodeString(strings.ReplaceAll(string(data), "-", ""))
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.BootID, err)
+	}
+
+	if len(seed) != 16 {
+		return fmt.Errorf("%s: expecting a UUID, got %d bytes", p.BootID, len(seed))
+	}
+
+	e.BootSeed = &seed
+
+	return nil
+}
+
+//nolint:gocritic
+func (c Linux) collectSoftware(e *eat.Eat, p LinuxPaths) error {
+	var osRelease map[string]string
+
+	for _, path := range p.OSRelease {
+		data, err := c.readFile(path)
+		if err != nil {
+			return err
+		}
+
+		if data != nil {
+			osRelease = parseOSRelease(data)
+			break
+		}
+	}
+
+	name, version := osRelease["NAME"], osRelease["VERSION_ID"]
+
+	if name == "" || version == "" {
+		release, err := c.readFile(p.KernelRelease)
+		if err != nil {
+			return err
+		}
+
+		if release == nil {
+			return nil
+		}
+
+		name, version = "Linux", string(release)
+	}
+
+	var swName eat.StringOrURI
+	if err := swName.FromString(name); err != nil {
+		return err
+	}
+
+	e.SoftwareName = &swName
+	e.SoftwareVersion = &eat.Version{Version: version}
+
+	return nil
+}
+
+// parseOSRelease parses the newline-separated KEY=VALUE assignments of an
+// os-release file, where values may be quoted
+func parseOSRelease(data []byte) map[string]string {
+	m := make(map[string]string)
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		if uq, err := strconv.Unquote(v); err == nil {
+			v = uq
+		} else {
+			v = strings.Trim(v, `'"`)
+		}
+
+		m[k] = v
+	}
+
+	return m
+}
+
+//nolint:gocritic
+func (c Linux) collectUEID(e *eat.Eat, p LinuxPaths) error {
+	data, err := c.readFile(p.MachineID)
+	if err != nil || data == nil {
+		return err
+	}
+
+	// 32 lower-case hexadecimal characters
+	machineID, err := hex.DecodeString(string(data))
+	if err != nil || len(machineID) != 16 {
+		return fmt.Errorf("%s: malformed machine ID", p.MachineID)
+	}
+
+	key := c.UEIDKey
+	if key == nil {
+		key = []byte(defaultUEIDKey)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(machineID)
+
+	ueid := append(eat.UEID{eat.UEIDTypeRAND}, mac.Sum(nil)...)
+	e.UEID = &ueid
+
+	return nil
+}
+
+//nolint:gocritic
+func (c Linux) collectMeasurements(e *eat.Eat, _ LinuxPaths) error {
+	if len(c.Files) == 0 {
+		return nil
+	}
+
+	alg := c.HashAlgorithm
+	if alg == 0 {
+		alg = swid.Sha256
+	}
+
+	var measurements []eat.Measurement
+	if e.Measurements != nil {
+		measurements = *e.Measurements
+	}
+
+	for _, f := range c.Files {
+		path, err := c.path(f)
+		if err != nil {
+			return err
+		}
+
+		mc, err := eat.NewMeasuredComponentFromFile(eat.ComponentID{Name: f}, alg, path)
+		if err != nil {
+			return fmt.Errorf("measuring %s: %w", f, err)
+		}
+
+		m, err := mc.ToMeasurement(measuredComponentContentFormat)
+		if err != nil {
+			return fmt.Errorf("measuring %s: %w", f, err)
+		}
+
+		measurements = append(measurements, *m)
+	}
+
+	e.Measurements = &measurements
+
+	return nil
+}
+
+//nolint:gocritic
+func (c Linux) collectIMA(e *eat.Eat, p LinuxPaths) error {
+	if !c.IMA {
+		return nil
+	}
+
+	path, err := c.path(p.IMALog)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := ParseIMAASCII(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.IMALog, err)
+	}
+
+	measurements, _, err := IMAMeasurements(entries)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.IMALog, err)
+	}
+
+	if e.Measurements != nil {
+		measurements = append(*e.Measurements, measurements...)
+	}
+
+	e.Measurements = &measurements
+
+	return nil
+}
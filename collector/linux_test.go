@@ -0,0 +1,111 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+)
+
+const testMachineID = "5b2a4c1e9d3f4a7b8c6e0d1f2a3b4c5d"
+
+func writeRootFile(t *testing.T, root, path, content string) {
+	p := filepath.Join(root, path)
+	require.Nil(t, os.MkdirAll(filepath.Dir(p), 0700))
+	require.Nil(t, os.WriteFile(p, []byte(content), 0600))
+}
+
+func newTestRoot(t *testing.T) string {
+	root := t.TempDir()
+
+	writeRootFile(t, root, "/proc/uptime", "350735.47 234388.90\n")
+	writeRootFile(t, root, "/proc/sys/kernel/random/boot_id", "6b8f7e3c-2d4a-4c8e-9f1b-0a5d3e7c9b21\n")
+	writeRootFile(t, root, "/proc/sys/kernel/osrelease", "6.8.0-45-generic\n")
+	writeRootFile(t, root, "/etc/machine-id", testMachineID+"\n")
+	writeRootFile(t, root, "/usr/lib/os-release", `# comment
+NAME="Ubuntu"
+VERSION_ID="24.04"
+ID=ubuntu
+`)
+	writeRootFile(t, root, "/boot/vmlinuz", "kernel image")
+
+	return root
+}
+
+func TestLinux_Collect(t *testing.T) {
+	c := Linux{Root: newTestRoot(t), Files: []string{"/boot/vmlinuz"}}
+
+	var e eat.Eat
+	require.Nil(t, c.Collect(&e))
+
+	assert.Equal(t, uint(350735), *e.Uptime)
+
+	seed, _ := hex.DecodeString("6b8f7e3c2d4a4c8e9f1b0a5d3e7c9b21")
+	assert.Equal(t, seed, *e.BootSeed)
+
+	assert.Equal(t, "Ubuntu", e.SoftwareName.String())
+	assert.Equal(t, "24.04", e.SoftwareVersion.Version)
+
+	machineID, _ := hex.DecodeString(testMachineID)
+	mac := hmac.New(sha256.New, []byte(defaultUEIDKey))
+	mac.Write(machineID)
+	assert.Equal(t, append(eat.UEID{eat.UEIDTypeRAND}, mac.Sum(nil)...), *e.UEID)
+	assert.Nil(t, e.UEID.Validate())
+
+	require.Len(t, *e.Measurements, 1)
+	m := (*e.Measurements)[0]
+	assert.Equal(t, measuredComponentContentFormat, m.Type)
+
+	var mc eat.MeasuredComponent
+	require.Nil(t, cbor.Unmarshal(m.Format, &mc))
+	assert.Equal(t, "/boot/vmlinuz", mc.Id.Name)
+	assert.Equal(t, uint64(swid.Sha256), mc.Measurement.Alg)
+	assert.Nil(t, mc.Verify([]byte("kernel image")))
+}
+
+func TestLinux_Collect_kernel_release(t *testing.T) {
+	root := newTestRoot(t)
+	require.Nil(t, os.Remove(filepath.Join(root, "/usr/lib/os-release")))
+
+	var e eat.Eat
+	require.Nil(t, Linux{Root: root}.Collect(&e))
+
+	assert.Equal(t, "Linux", e.SoftwareName.String())
+	assert.Equal(t, "6.8.0-45-generic", e.SoftwareVersion.Version)
+	assert.Nil(t, e.Measurements)
+}
+
+func TestLinux_Collect_missing(t *testing.T) {
+	var e eat.Eat
+	require.Nil(t, Linux{Root: t.TempDir()}.Collect(&e))
+	assert.Equal(t, eat.Eat{}, e)
+}
+
+func TestLinux_Collect_NG(t *testing.T) {
+	root := newTestRoot(t)
+
+	var e eat.Eat
+	err := Linux{Root: root, Files: []string{"/boot/missing"}}.Collect(&e)
+	assert.ErrorContains(t, err, "measuring /boot/missing: ")
+
+	err = Linux{Root: root, Files: []string{"boot/vmlinuz"}}.Collect(&e)
+	assert.EqualError(t, err, `path "boot/vmlinuz" is not absolute`)
+
+	writeRootFile(t, root, "/etc/machine-id", "not hex")
+	err = Linux{Root: root}.Collect(&e)
+	assert.EqualError(t, err, "/etc/machine-id: malformed machine ID")
+
+	err = Linux{Root: root, Paths: LinuxPaths{Uptime: "/etc/machine-id"}}.Collect(&e)
+	assert.ErrorContains(t, err, "/etc/machine-id: ")
+}
@@ -0,0 +1,496 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // SHA-1 PCR banks must be replayed
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+	"golang.org/x/crypto/sha3"
+)
+
+// TPM hash algorithm identifiers (TPM_ALG_ID) used by PCR banks
+const (
+	TPMAlgSHA1     uint16 = 0x0004
+	TPMAlgSHA256   uint16 = 0x000b
+	TPMAlgSHA384   uint16 = 0x000c
+	TPMAlgSHA512   uint16 = 0x000d
+	TPMAlgSHA3_256 uint16 = 0x0027
+	TPMAlgSHA3_384 uint16 = 0x0028
+	TPMAlgSHA3_512 uint16 = 0x0029
+)
+
+type tpmAlgorithm struct {
+	newHash func() hash.Hash
+	// namedInfo is the Named Information identifier, or zero if there is none
+	namedInfo uint64
+}
+
+var tpmAlgorithms = map[uint16]tpmAlgorithm{
+	TPMAlgSHA1:     {sha1.New, 0},
+	TPMAlgSHA256:   {sha256.New, swid.Sha256},
+	TPMAlgSHA384:   {sha512.New384, swid.Sha384},
+	TPMAlgSHA512:   {sha512.New, swid.Sha512},
+	TPMAlgSHA3_256: {sha3.New256, swid.Sha3_256},
+	TPMAlgSHA3_384: {sha3.New384, swid.Sha3_384},
+	TPMAlgSHA3_512: {sha3.New512, swid.Sha3_512},
+}
+
+// Event types (TCG PC Client Platform Firmware Profile)
+const (
+	EvNoAction                   uint32 = 0x00000003
+	EvSeparator                  uint32 = 0x00000004
+	EvSCRTMVersion               uint32 = 0x00000008
+	EvEFIVariableDriverConfig    uint32 = 0x80000001
+	EvEFIVariableBoot            uint32 = 0x80000002
+	EvEFIBootServicesApplication uint32 = 0x80000003
+	EvEFIVariableBoot2           uint32 = 0x8000000c
+	EvEFIVariableAuthority       uint32 = 0x800000e0
+)
+
+var tcgEventTypeNames = map[uint32]string{
+	0x00000000:                   "EV_PREBOOT_CERT",
+	0x00000001:                   "EV_POST_CODE",
+	0x00000002:                   "EV_UNUSED",
+	EvNoAction:                   "EV_NO_ACTION",
+	EvSeparator:                  "EV_SEPARATOR",
+	0x00000005:                   "EV_ACTION",
+	0x00000006:                   "EV_EVENT_TAG",
+	0x00000007:                   "EV_S_CRTM_CONTENTS",
+	EvSCRTMVersion:               "EV_S_CRTM_VERSION",
+	0x00000009:                   "EV_CPU_MICROCODE",
+	0x0000000a:                   "EV_PLATFORM_CONFIG_FLAGS",
+	0x0000000b:                   "EV_TABLE_OF_DEVICES",
+	0x0000000c:                   "EV_COMPACT_HASH",
+	0x0000000d:                   "EV_IPL",
+	0x0000000e:                   "EV_IPL_PARTITION_DATA",
+	0x0000000f:                   "EV_NONHOST_CODE",
+	0x00000010:                   "EV_NONHOST_CONFIG",
+	0x00000011:                   "EV_NONHOST_INFO",
+	0x00000012:                   "EV_OMIT_BOOT_DEVICE_EVENTS",
+	EvEFIVariableDriverConfig:    "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	EvEFIVariableBoot:            "EV_EFI_VARIABLE_BOOT",
+	EvEFIBootServicesApplication: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	0x80000004:                   "EV_EFI_BOOT_SERVICES_DRIVER",
+	0x80000005:                   "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	0x80000006:                   "EV_EFI_GPT_EVENT",
+	0x80000007:                   "EV_EFI_ACTION",
+	0x80000008:                   "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	0x80000009:                   "EV_EFI_HANDOFF_TABLES",
+	0x8000000a:                   "EV_EFI_PLATFORM_FIRMWARE_BLOB2",
+	0x8000000b:                   "EV_EFI_HANDOFF_TABLES2",
+	EvEFIVariableBoot2:           "EV_EFI_VARIABLE_BOOT2",
+	0x80000010:                   "EV_EFI_HCRTM_EVENT",
+	EvEFIVariableAuthority:       "EV_EFI_VARIABLE_AUTHORITY",
+}
+
+// TCGEventTypeName returns the name of the supplied event type, e.g.
+// "EV_SEPARATOR", or its hexadecimal value if it is unknown
+func TCGEventTypeName(t uint32) string {
+	if name, ok := tcgEventTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("EV_0x%08x", t)
+}
+
+var (
+	specIDSignature          = []byte("Spec ID Event03\x00")
+	startupLocalitySignature = []byte("StartupLocality\x00")
+)
+
+// maxTCGEventSize bounds the size of the event data
+const maxTCGEventSize = 1 << 24
+
+// TCGEvent is an event of a crypto-agile event log
+type TCGEvent struct {
+	// PCR is the index of the PCR the event was extended into
+	PCR uint32
+	// Type is the event type, e.g. EvSeparator
+	Type uint32
+	// Digests are the event digests, by TPM hash algorithm identifier
+	Digests map[uint16][]byte
+	// Data is the event data
+	Data []byte
+}
+
+// TCGEventLog is a TCG PC Client crypto-agile (TPM 2.0) event log
+type TCGEventLog struct {
+	// DigestSizes are the sizes of the digests of each PCR bank, by TPM hash
+	// algorithm identifier, as declared in the Spec ID event
+	DigestSizes map[uint16]uint16
+	// StartupLocality is the locality from which the TPM was started, as
+	// recorded in the StartupLocality event, or zero
+	StartupLocality uint8
+	// Events are the events that follow the Spec ID event
+	Events []TCGEvent
+}
+
+/*
+TCG_PCClientPCREvent (first event, SHA-1 format):
+  PCRIndex u32 | EventType u32 | Digest [20] | EventSize u32 | Event
+
+TCG_EfiSpecIdEvent:
+  Signature [16] | platformClass u32 | specVersionMinor u8 |
+  specVersionMajor u8 | specErrata u8 | uintnSize u8 |
+  numberOfAlgorithms u32 | { algorithmId u16 | digestSize u16 }* |
+  vendorInfoSize u8 | vendorInfo
+
+TCG_PCR_EVENT2:
+  PCRIndex u32 | EventType u32 |
+  count u32 | { hashAlg u16 | digest [size of hashAlg] }* |
+  EventSize u32 | Event
+*/
+
+// ParseTCGEventLog parses a binary crypto-agile event log, e.g.
+// /sys/kernel/security/tpm0/binary_bios_measurements
+func ParseTCGEventLog(r io.Reader) (*TCGEventLog, error) {
+	var l TCGEventLog
+
+	if err := l.parseSpecIDEvent(r); err != nil {
+		return nil, fmt.Errorf("Spec ID event: %w", err)
+	}
+
+	for n := 1; ; n++ {
+		e, err := l.parseEvent(r)
+		if errors.Is(err, io.EOF) {
+			return &l, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("event at index %d: %w", n, err)
+		}
+
+		if e.Type == EvNoAction {
+			if locality, ok := parseStartupLocality(e.Data); ok {
+				l.StartupLocality = locality
+			}
+		}
+
+		l.Events = append(l.Events, *e)
+	}
+}
+
+func (l *TCGEventLog) parseSpecIDEvent(r io.Reader) error {
+	var h struct {
+		PCR    uint32
+		Type   uint32
+		Digest [20]byte
+		Size   uint32
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	if h.Type != EvNoAction || h.Size > maxTCGEventSize {
+		return errors.New("not a crypto-agile event log")
+	}
+
+	data := make([]byte, h.Size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	if !bytes.HasPrefix(data, specIDSignature) {
+		return errors.New("not a crypto-agile event log")
+	}
+
+	br := bytes.NewReader(data[len(specIDSignature):])
+
+	var s struct {
+		PlatformClass uint32
+		Minor, Major  uint8
+		Errata        uint8
+		UintnSize     uint8
+		NumAlgorithms uint32
+	}
+
+	if err := binary.Read(br, binary.LittleEndian, &s); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	if s.NumAlgorithms == 0 || int(s.NumAlgorithms)*4 > br.Len() {
+		return fmt.Errorf("invalid number of algorithms %d", s.NumAlgorithms)
+	}
+
+	l.DigestSizes = make(map[uint16]uint16, s.NumAlgorithms)
+
+	for i := uint32(0); i < s.NumAlgorithms; i++ {
+		var a struct{ ID, Size uint16 }
+		if err := binary.Read(br, binary.LittleEndian, &a); err != nil {
+			return unexpectedEOF(err)
+		}
+		l.DigestSizes[a.ID] = a.Size
+	}
+
+	return nil
+}
+
+// parseEvent returns io.EOF only if there are no more events
+func (l *TCGEventLog) parseEvent(r io.Reader) (*TCGEvent, error) {
+	var h struct {
+		PCR   uint32
+		Type  uint32
+		Count uint32
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &h.PCR); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &h.Type); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &h.Count); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if h.Count > uint32(len(l.DigestSizes)) {
+		return nil, fmt.Errorf("%d digests, only %d banks declared", h.Count, len(l.DigestSizes))
+	}
+
+	e := TCGEvent{PCR: h.PCR, Type: h.Type, Digests: make(map[uint16][]byte, h.Count)}
+
+	for i := uint32(0); i < h.Count; i++ {
+		var alg uint16
+		if err := binary.Read(r, binary.LittleEndian, &alg); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		size, ok := l.DigestSizes[alg]
+		if !ok {
+			return nil, fmt.Errorf("undeclared hash algorithm 0x%04x", alg)
+		}
+
+		digest := make([]byte, size)
+		if _, err := io.ReadFull(r, digest); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		e.Digests[alg] = digest
+	}
+
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if size > maxTCGEventSize {
+		return nil, fmt.Errorf("event size %d exceeds %d", size, maxTCGEventSize)
+	}
+
+	e.Data = make([]byte, size)
+	if _, err := io.ReadFull(r, e.Data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return &e, nil
+}
+
+func parseStartupLocality(data []byte) (uint8, bool) {
+	if len(data) != len(startupLocalitySignature)+1 || !bytes.HasPrefix(data, startupLocalitySignature) {
+		return 0, false
+	}
+	return data[len(data)-1], true
+}
+
+// ReplayPCRs recomputes the values of the PCRs of the supplied bank by
+// extending the event digests in order, starting from the reset values.  The
+// result can be compared with the PCR values quoted by the TPM.
+//
+//nolint:gocritic
+func (l TCGEventLog) ReplayPCRs(alg uint16) (map[uint32][]byte, error) {
+	a, ok := tpmAlgorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm 0x%04x", alg)
+	}
+
+	if _, ok := l.DigestSizes[alg]; !ok {
+		return nil, fmt.Errorf("no 0x%04x bank in event log", alg)
+	}
+
+	pcrs := make(map[uint32][]byte)
+
+	for i, e := range l.Events {
+		if e.Type == EvNoAction {
+			continue
+		}
+
+		digest, ok := e.Digests[alg]
+		if !ok {
+			return nil, fmt.Errorf("event at index %d: no 0x%04x digest", i+1, alg)
+		}
+
+		pcr, ok := pcrs[e.PCR]
+		if !ok {
+			pcr = make([]byte, a.newHash().Size())
+			if e.PCR == 0 {
+				pcr[len(pcr)-1] = l.StartupLocality
+			}
+		}
+
+		h := a.newHash()
+		h.Write(pcr)
+		h.Write(digest)
+		pcrs[e.PCR] = h.Sum(nil)
+	}
+
+	return pcrs, nil
+}
+
+// CheckPCRs replays the log (see ReplayPCRs) and checks that the result
+// matches the supplied PCR values of the same bank.  Every supplied PCR must
+// have been extended by the log.
+//
+//nolint:gocritic
+func (l TCGEventLog) CheckPCRs(alg uint16, expected map[uint32][]byte) error {
+	pcrs, err := l.ReplayPCRs(alg)
+	if err != nil {
+		return err
+	}
+
+	indices := make([]uint32, 0, len(expected))
+	for i := range expected {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	for _, i := range indices {
+		v, ok := pcrs[i]
+		if !ok {
+			return fmt.Errorf("PCR %d is not extended by the event log", i)
+		}
+
+		if !bytes.Equal(v, expected[i]) {
+			return fmt.Errorf("PCR %d does not match the event log: expected %x, replayed %x", i, expected[i], v)
+		}
+	}
+
+	return nil
+}
+
+// MeasuredComponent returns the receiver event as a measured component whose
+// measurement is the event digest of the supplied bank.  The component is
+// named after the event type and, for UEFI variable events, the variable
+// name.  The version of EV_S_CRTM_VERSION events is taken from the event data.
+//
+//nolint:gocritic
+func (e TCGEvent) MeasuredComponent(alg uint16) (*eat.MeasuredComponent, error) {
+	a, ok := tpmAlgorithms[alg]
+	if !ok || a.namedInfo == 0 {
+		return nil, fmt.Errorf("no Named Information hash algorithm for 0x%04x", alg)
+	}
+
+	digest, ok := e.Digests[alg]
+	if !ok {
+		return nil, fmt.Errorf("no 0x%04x digest", alg)
+	}
+
+	d := eat.Digest{Alg: a.namedInfo, Value: digest}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	id := eat.ComponentID{Name: TCGEventTypeName(e.Type)}
+
+	switch e.Type {
+	case EvEFIVariableDriverConfig, EvEFIVariableBoot, EvEFIVariableBoot2, EvEFIVariableAuthority:
+		if name, ok := efiVariableName(e.Data); ok {
+			id.Name += ":" + name
+		}
+	case EvSCRTMVersion:
+		if v, ok := decodeUTF16(e.Data); ok && v != "" {
+			id.Version = &eat.Version{Version: v}
+		}
+	}
+
+	return &eat.MeasuredComponent{Id: id, Measurement: &d}, nil
+}
+
+/*
+UEFI_VARIABLE_DATA:
+  VariableName GUID [16] | UnicodeNameLength u64 | VariableDataLength u64 |
+  UnicodeName [UnicodeNameLength UTF-16 chars] | VariableData
+*/
+
+func efiVariableName(data []byte) (string, bool) {
+	const header = 16 + 8 + 8
+
+	if len(data) < header {
+		return "", false
+	}
+
+	n := binary.LittleEndian.Uint64(data[16:24])
+	if n > uint64(len(data)-header)/2 {
+		return "", false
+	}
+
+	return decodeUTF16(data[header : header+2*int(n)])
+}
+
+// decodeUTF16 decodes the supplied UTF-16LE string, dropping the trailing NULs
+func decodeUTF16(data []byte) (string, bool) {
+	if len(data)%2 != 0 {
+		return "", false
+	}
+
+	u := make([]uint16, 0, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		u = append(u, binary.LittleEndian.Uint16(data[i:]))
+	}
+
+	for len(u) > 0 && u[len(u)-1] == 0 {
+		u = u[:len(u)-1]
+	}
+
+	return string(utf16.Decode(u)), true
+}
+
+// Submods groups the events of the receiver log by PCR and returns one submod
+// per PCR, named "pcr<index>", whose Measurements claim holds the events as
+// measured components (see TCGEvent.MeasuredComponent), in log order.
+// EV_NO_ACTION events, which are not extended, are left out.
+//
+//nolint:gocritic
+func (l TCGEventLog) Submods(alg uint16) (eat.Submods, error) {
+	groups := make(map[uint32][]eat.Measurement)
+
+	for i, e := range l.Events {
+		if e.Type == EvNoAction {
+			continue
+		}
+
+		mc, err := e.MeasuredComponent(alg)
+		if err != nil {
+			return nil, fmt.Errorf("event at index %d: %w", i+1, err)
+		}
+
+		m, err := mc.ToMeasurement(measuredComponentContentFormat)
+		if err != nil {
+			return nil, fmt.Errorf("event at index %d: %w", i+1, err)
+		}
+
+		groups[e.PCR] = append(groups[e.PCR], *m)
+	}
+
+	var submods eat.Submods
+
+	for pcr, measurements := range groups {
+		if err := submods.Add(fmt.Sprintf("pcr%d", pcr), eat.Eat{Measurements: &measurements}); err != nil {
+			return nil, err
+		}
+	}
+
+	return submods, nil
+}
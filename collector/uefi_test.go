@@ -0,0 +1,137 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package collector
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/eat"
+	"github.com/veraison/swid"
+)
+
+// the testdata event log has SHA-1 and SHA-256 banks, a StartupLocality event
+// for locality 3, and separators in PCRs 0 to 7.  The SHA-256 PCR values below
+// were computed independently of the parser.
+var testTCGPCRValues = map[uint32]string{
+	0: "af2f4a34dabb259c6c45c437261168e23c6c148e6be0bac89ed49dd47f032267",
+	4: "436a9593837e4147a076edbb7bb58b65d03f56b1a4f544d68ac75a9b2a2b7a16",
+	7: "ecca06b5440a56b6021f831405531cf2af1b94ed19beafd4709e63e588b81056",
+}
+
+func parseTestTCGEventLog(t *testing.T) *TCGEventLog {
+	l, err := ParseTCGEventLog(bytes.NewReader(readTestData(t, "binary_bios_measurements")))
+	require.Nil(t, err)
+	return l
+}
+
+func testTCGPCRs(t *testing.T) map[uint32][]byte {
+	pcrs := make(map[uint32][]byte)
+	for i, v := range testTCGPCRValues {
+		b, err := hex.DecodeString(v)
+		require.Nil(t, err)
+		pcrs[i] = b
+	}
+	return pcrs
+}
+
+func TestParseTCGEventLog(t *testing.T) {
+	l := parseTestTCGEventLog(t)
+
+	assert.Equal(t, map[uint16]uint16{TPMAlgSHA1: 20, TPMAlgSHA256: 32}, l.DigestSizes)
+	assert.Equal(t, uint8(3), l.StartupLocality)
+	require.Len(t, l.Events, 13)
+
+	assert.Equal(t, EvNoAction, l.Events[0].Type)
+	assert.Equal(t, EvSCRTMVersion, l.Events[1].Type)
+	assert.Equal(t, "EV_EFI_PLATFORM_FIRMWARE_BLOB", TCGEventTypeName(l.Events[2].Type))
+	assert.Equal(t, uint32(7), l.Events[3].PCR)
+	assert.Len(t, l.Events[3].Digests[TPMAlgSHA1], 20)
+	assert.Len(t, l.Events[3].Digests[TPMAlgSHA256], 32)
+	assert.Equal(t, "EV_0x00001234", TCGEventTypeName(0x1234))
+}
+
+func TestTCGEventLog_ReplayPCRs(t *testing.T) {
+	l := parseTestTCGEventLog(t)
+
+	pcrs, err := l.ReplayPCRs(TPMAlgSHA256)
+	require.Nil(t, err)
+	assert.Len(t, pcrs, 8)
+
+	sha1PCRs, err := l.ReplayPCRs(TPMAlgSHA1)
+	require.Nil(t, err)
+	assert.Len(t, sha1PCRs[0], 20)
+
+	expected := testTCGPCRs(t)
+	assert.Nil(t, l.CheckPCRs(TPMAlgSHA256, expected))
+
+	expected[4][0] ^= 0xff
+	err = l.CheckPCRs(TPMAlgSHA256, expected)
+	assert.ErrorContains(t, err, "PCR 4 does not match the event log: ")
+
+	err = l.CheckPCRs(TPMAlgSHA256, map[uint32][]byte{10: make([]byte, 32)})
+	assert.EqualError(t, err, "PCR 10 is not extended by the event log")
+
+	_, err = l.ReplayPCRs(TPMAlgSHA384)
+	assert.EqualError(t, err, "no 0x000c bank in event log")
+
+	_, err = l.ReplayPCRs(0x0012)
+	assert.EqualError(t, err, "unsupported hash algorithm 0x0012")
+}
+
+func TestTCGEventLog_Submods(t *testing.T) {
+	l := parseTestTCGEventLog(t)
+
+	submods, err := l.Submods(TPMAlgSHA256)
+	require.Nil(t, err)
+	require.Len(t, submods, 8)
+
+	pcr0, ok := submods.Get("pcr0").(eat.Eat)
+	require.True(t, ok)
+	require.Len(t, *pcr0.Measurements, 3)
+
+	var names []string
+	for _, m := range *pcr0.Measurements {
+		var mc eat.MeasuredComponent
+		require.Nil(t, cbor.Unmarshal(m.Format, &mc))
+		assert.Equal(t, swid.Sha256, mc.Measurement.Alg)
+		names = append(names, mc.Id.Name)
+	}
+	assert.Equal(t, []string{"EV_S_CRTM_VERSION", "EV_EFI_PLATFORM_FIRMWARE_BLOB", "EV_SEPARATOR"}, names)
+
+	mc, err := l.Events[1].MeasuredComponent(TPMAlgSHA256)
+	require.Nil(t, err)
+	assert.Equal(t, "1.2.3", mc.Id.Version.Version)
+
+	mc, err = l.Events[2].MeasuredComponent(TPMAlgSHA256)
+	require.Nil(t, err)
+	assert.Nil(t, mc.Verify([]byte("firmware volume")))
+
+	mc, err = l.Events[3].MeasuredComponent(TPMAlgSHA256)
+	require.Nil(t, err)
+	assert.Equal(t, "EV_EFI_VARIABLE_DRIVER_CONFIG:SecureBoot", mc.Id.Name)
+	assert.Nil(t, mc.Verify(l.Events[3].Data))
+
+	_, err = l.Submods(TPMAlgSHA1)
+	assert.EqualError(t, err, "event at index 2: no Named Information hash algorithm for 0x0004")
+}
+
+func TestParseTCGEventLog_NG(t *testing.T) {
+	data := readTestData(t, "binary_bios_measurements")
+
+	_, err := ParseTCGEventLog(bytes.NewReader(data[:len(data)-1]))
+	assert.EqualError(t, err, "event at index 13: unexpected EOF")
+
+	_, err = ParseTCGEventLog(bytes.NewReader(data[:10]))
+	assert.EqualError(t, err, "Spec ID event: unexpected EOF")
+
+	notAgile := bytes.Clone(data)
+	notAgile[32] = 'X' // corrupt the Spec ID signature
+	_, err = ParseTCGEventLog(bytes.NewReader(notAgile))
+	assert.EqualError(t, err, "Spec ID event: not a crypto-agile event log")
+}
@@ -30,3 +30,11 @@ func isJSONArray(data []byte) bool {
 
 	return data[0] == '['
 }
+
+func isCBORMap(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	return (data[0] & 0xe0) == 0xa0
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"errors"
+	"fmt"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	cose "github.com/veraison/go-cose"
+)
+
+// ConstrainedDeviceProfileID identifies the Constrained Device Standard
+// Profile defined in RFC 9711, Section 7.2
+const ConstrainedDeviceProfileID = "urn:ietf:rfc:rfc9711"
+
+const (
+	cborTagCWT       = 61
+	cborTagCOSESign1 = 18
+)
+
+// constrainedDeviceAlgorithms are the signing algorithms allowed by the
+// Constrained Device Standard Profile
+var constrainedDeviceAlgorithms = map[cose.Algorithm]bool{
+	cose.AlgorithmES256: true,
+	cose.AlgorithmES384: true,
+	cose.AlgorithmES512: true,
+}
+
+// ConstrainedDeviceProfile implements the Constrained Device Standard Profile
+// (RFC 9711, Section 7.2).  It is registered by default, so that decoded
+// claims-sets that carry its identifier are checked automatically.  Use
+// CheckToken to certify a complete encoded token.
+//
+// The profile mandates:
+//
+//   - CBOR encoding with definite-length items in preferred serialization only
+//     (no JSON)
+//   - COSE_Sign1 protection, optionally wrapped in a CWT tag, using ES256,
+//     ES384 or ES512 and an attached payload (no encryption, no detached EAT
+//     bundles)
+//   - a COSE kid or a UEID claim to identify the verification key
+//   - a single nonce between 8 and 64 bytes
+type ConstrainedDeviceProfile struct{}
+
+// ID returns the identifier of the Constrained Device Standard Profile
+func (ConstrainedDeviceProfile) ID() string {
+	return ConstrainedDeviceProfileID
+}
+
+// Rules returns the claim rules of the Constrained Device Standard Profile
+func (ConstrainedDeviceProfile) Rules() ProfileRules {
+	return ProfileRules{
+		RequiredClaims: []string{"eat_nonce"},
+		MinNonceSize:   MinNonceSize,
+		MaxNonceSize:   MaxNonceSize,
+		Encodings:      EncodingCBOR,
+	}
+}
+
+// Validate checks that exactly one nonce is present
+func (ConstrainedDeviceProfile) Validate(e *Eat) error {
+	if e.Nonce != nil && e.Nonce.Len() != 1 {
+		return fmt.Errorf("exactly one nonce is required, found %d", e.Nonce.Len())
+	}
+	return nil
+}
+
+// CheckToken checks the supplied encoded token against the Constrained Device
+// Standard Profile and returns the list of all conformance failures found, or
+// nil if the token conforms.  The signature is not verified.
+func (p ConstrainedDeviceProfile) CheckToken(data []byte) []error {
+	var errs []error
+
+	if err := dm.Wellformed(data); err != nil {
+		return []error{fmt.Errorf("token is not well-formed definite-length CBOR: %w", err)}
+	}
+
+	sign1, err := p.unwrap(data)
+	if err != nil {
+		return []error{err}
+	}
+
+	var msg cose.Sign1Message
+	if err := msg.UnmarshalCBOR(sign1); err != nil {
+		return []error{fmt.Errorf("malformed COSE_Sign1: %w", err)}
+	}
+
+	if err := checkPreferredSerialization(data); err != nil {
+		errs = append(errs, fmt.Errorf("token is not in preferred serialization: %w", err))
+	}
+
+	// the protected header is a byte string wrapping the header map
+	var protected []byte
+	if err := dm.Unmarshal(msg.Headers.RawProtected, &protected); err == nil && len(protected) > 0 {
+		if err := checkPreferredSerialization(protected); err != nil {
+			errs = append(errs, fmt.Errorf("protected header is not in preferred serialization: %w", err))
+		}
+	}
+
+	alg, err := msg.Headers.Protected.Algorithm()
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("signing algorithm: %w", err))
+	case !constrainedDeviceAlgorithms[alg]:
+		errs = append(errs, fmt.Errorf("signing algorithm %s is not allowed (ES256, ES384 or ES512 required)", alg))
+	}
+
+	if msg.Payload == nil {
+		errs = append(errs, errors.New("detached payload is not allowed"))
+		return errs
+	}
+
+	var claims Eat
+	if err := dm.Unmarshal(msg.Payload, &claims); err != nil {
+		errs = append(errs, fmt.Errorf("malformed claims-set: %w", err))
+		return errs
+	}
+
+	if err := checkPreferredSerialization(msg.Payload); err != nil {
+		errs = append(errs, fmt.Errorf("claims-set is not in preferred serialization: %w", err))
+	}
+
+	if !hasKeyID(msg.Headers) && claims.UEID == nil {
+		errs = append(errs, errors.New("either a COSE kid or a UEID claim is required to identify the verification key"))
+	}
+
+	if claims.Profile == nil {
+		errs = append(errs, errors.New("missing eat_profile claim"))
+	} else if id, _ := claims.Profile.Get(); id != ConstrainedDeviceProfileID {
+		errs = append(errs, fmt.Errorf("eat_profile is %q, expecting %q", id, ConstrainedDeviceProfileID))
+	}
+
+	errs = append(errs, claims.checkProfileRules(p.Rules(), EncodingCBOR)...)
+
+	if err := p.Validate(&claims); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// unwrap strips the optional CWT tag and checks that what is left is a
+// COSE_Sign1 tagged message
+func (ConstrainedDeviceProfile) unwrap(data []byte) ([]byte, error) {
+	if len(data) == 0 || !isCBORTag(data) {
+		return nil, errors.New("token is not a tagged COSE_Sign1 (untagged CBOR or JSON)")
+	}
+
+	var tag cbor.RawTag
+	if err := dm.Unmarshal(data, &tag); err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+
+	if tag.Number == cborTagCWT {
+		data = tag.Content
+		if len(data) == 0 || !isCBORTag(data) {
+			return nil, errors.New("CWT tag does not wrap a tagged COSE message")
+		}
+		if err := dm.Unmarshal(data, &tag); err != nil {
+			return nil, fmt.Errorf("malformed token: %w", err)
+		}
+	}
+
+	if tag.Number != cborTagCOSESign1 {
+		return nil, fmt.Errorf("COSE_Sign1 required, found CBOR tag %d", tag.Number)
+	}
+
+	return data, nil
+}
+
+func hasKeyID(h cose.Headers) bool {
+	for _, m := range []map[any]any{h.Protected, h.Unprotected} {
+		if kid, ok := m[cose.HeaderLabelKeyID].([]byte); ok && len(kid) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	if err := RegisterProfile(ConstrainedDeviceProfile{}); err != nil {
+		panic(err)
+	}
+}
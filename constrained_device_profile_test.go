@@ -0,0 +1,170 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func signTestToken(t *testing.T, e Eat, alg cose.Algorithm, kid []byte, cwtTag bool) []byte {
+	payload, err := em.Marshal(e)
+	require.Nil(t, err)
+
+	return signTestPayload(t, payload, alg, kid, cwtTag)
+}
+
+func signTestPayload(t *testing.T, payload []byte, alg cose.Algorithm, kid []byte, cwtTag bool) []byte {
+	var key crypto.Signer = newTestP256Key(t)
+	if alg == cose.AlgorithmEdDSA {
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		require.Nil(t, err)
+		key = edKey
+	}
+
+	signer, err := cose.NewSigner(alg, key)
+	require.Nil(t, err)
+
+	msg := cose.NewSign1Message()
+	msg.Headers.Protected.SetAlgorithm(alg)
+	if kid != nil {
+		msg.Headers.Unprotected[cose.HeaderLabelKeyID] = kid
+	}
+	msg.Payload = payload
+	require.Nil(t, msg.Sign(rand.Reader, nil, signer))
+
+	data, err := msg.MarshalCBOR()
+	require.Nil(t, err)
+
+	if cwtTag {
+		data = append([]byte{0xd8, 0x3d}, data...)
+	}
+
+	return data
+}
+
+func TestConstrainedDeviceProfile_CheckToken_OK(t *testing.T) {
+	var p ConstrainedDeviceProfile
+
+	tv := signTestToken(t, newTestProfileEat(t, ConstrainedDeviceProfileID), cose.AlgorithmES256, nil, true)
+	assert.Nil(t, p.CheckToken(tv))
+
+	// kid instead of UEID, no CWT tag
+	e := newTestProfileEat(t, ConstrainedDeviceProfileID)
+	e.UEID = nil
+	tv = signTestToken(t, e, cose.AlgorithmES256, []byte("kid"), false)
+	assert.Nil(t, p.CheckToken(tv))
+}
+
+func TestConstrainedDeviceProfile_CheckToken_NG(t *testing.T) {
+	var p ConstrainedDeviceProfile
+
+	e := newTestProfileEat(t, ConstrainedDeviceProfileID)
+	e.UEID = nil
+	e.Profile = nil
+	require.Nil(t, e.Nonce.Add(nonceBytes))
+
+	tv := signTestToken(t, e, cose.AlgorithmEdDSA, nil, true)
+
+	errs := p.CheckToken(tv)
+	require.Len(t, errs, 4)
+	assert.EqualError(t, errs[0], "signing algorithm EdDSA is not allowed (ES256, ES384 or ES512 required)")
+	assert.EqualError(t, errs[1], "either a COSE kid or a UEID claim is required to identify the verification key")
+	assert.EqualError(t, errs[2], "missing eat_profile claim")
+	assert.EqualError(t, errs[3], "exactly one nonce is required, found 2")
+}
+
+func TestConstrainedDeviceProfile_CheckToken_preferred_serialization(t *testing.T) {
+	var p ConstrainedDeviceProfile
+
+	payload, err := em.Marshal(newTestProfileEat(t, ConstrainedDeviceProfileID))
+	require.Nil(t, err)
+	require.Equal(t, byte(0xa3), payload[0])
+
+	// the map of 3 claims with its length in an additional byte
+	payload = append([]byte{0xb8, 0x03}, payload[1:]...)
+
+	errs := p.CheckToken(signTestPayload(t, payload, cose.AlgorithmES256, nil, true))
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0],
+		"claims-set is not in preferred serialization: non-preferred argument encoding at offset 0")
+}
+
+func TestCheckPreferredSerialization(t *testing.T) {
+	for _, tv := range []struct {
+		data     []byte
+		expected string
+	}{
+		{[]byte{0x17}, ""},
+		{[]byte{0x18, 0x18}, ""},
+		{[]byte{0x18, 0x17}, "non-preferred argument encoding at offset 0"},
+		{[]byte{0x39, 0x00, 0xff}, "non-preferred argument encoding at offset 0"},
+		{[]byte{0x82, 0x01, 0x1a, 0x00, 0x00, 0xff, 0xff}, "non-preferred argument encoding at offset 2"},
+		{[]byte{0xd9, 0x00, 0x3d, 0x00}, "non-preferred argument encoding at offset 0"},
+		{[]byte{0x59, 0x00, 0x01, 0x00}, "non-preferred argument encoding at offset 0"},
+		{[]byte{0xf9, 0x3c, 0x00}, ""}, // 1.0
+		{[]byte{0xfa, 0x3f, 0x80, 0x00, 0x00}, "non-preferred floating-point encoding at offset 0"}, // 1.0
+		{[]byte{0xfa, 0x47, 0xc3, 0x50, 0x00}, ""},                                                  // 100000.0
+		{[]byte{0xfb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}, "non-preferred floating-point encoding at offset 0"},
+		{[]byte{0xfb, 0x3f, 0xb9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a}, ""}, // 0.1
+	} {
+		err := checkPreferredSerialization(tv.data)
+		if tv.expected == "" {
+			assert.Nil(t, err, "%x", tv.data)
+		} else {
+			assert.EqualError(t, err, tv.expected, "%x", tv.data)
+		}
+	}
+}
+
+func TestConstrainedDeviceProfile_CheckToken_Envelope(t *testing.T) {
+	var p ConstrainedDeviceProfile
+
+	errs := p.CheckToken([]byte(`{"eat_nonce":"AAAAAAAAAAA"}`))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "token is not well-formed definite-length CBOR")
+
+	// tag(61)(tag(98)(...)) -- COSE_Sign
+	errs = p.CheckToken([]byte{0xd8, 0x3d, 0xd8, 0x62, 0x80})
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "COSE_Sign1 required, found CBOR tag 98")
+
+	// bare claims-set
+	claims, err := newTestProfileEat(t, ConstrainedDeviceProfileID).ToCBOR()
+	require.Nil(t, err)
+	errs = p.CheckToken(claims)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "token is not a tagged COSE_Sign1 (untagged CBOR or JSON)")
+
+	// indefinite-length map
+	errs = p.CheckToken([]byte{0xbf, 0xff})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "token is not well-formed definite-length CBOR")
+}
+
+func TestConstrainedDeviceProfile_Decode(t *testing.T) {
+	e := newTestProfileEat(t, ConstrainedDeviceProfileID)
+
+	data, err := e.ToJSON()
+	require.Nil(t, err)
+
+	var actual Eat
+	assert.EqualError(t, actual.FromJSON(data),
+		"profile "+ConstrainedDeviceProfileID+": JSON encoding is not allowed by the profile",
+	)
+
+	short := sha256.Sum256(nil)
+	e.Nonce = &Nonce{nonce{short[:4]}}
+	assert.EqualError(t, e.Validate(),
+		"eat_nonce: found invalid nonce at index 0: a nonce must be between 8 and 64 bytes long; found 4\n"+
+			"profile "+ConstrainedDeviceProfileID+": nonce at index 0 has size 4, outside the range allowed by the profile [8, 64]",
+	)
+}
@@ -0,0 +1,475 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+EvidenceBundles ::= SEQUENCE SIZE (1..MAX) OF EvidenceBundle
+
+EvidenceBundle ::= SEQUENCE {
+	evidences SEQUENCE SIZE (1..MAX) OF EvidenceStatement,
+	certs SEQUENCE SIZE (1..MAX) OF CertificateChoices OPTIONAL
+}
+
+EvidenceStatement ::= SEQUENCE {
+	type   EVIDENCE-STATEMENT.&id({EvidenceStatementSet}),
+	stmt   EVIDENCE-STATEMENT.&Type({EvidenceStatementSet}{@type}),
+	hint   UTF8String OPTIONAL
+}
+
+CMW ::= CHOICE {
+	json UTF8String,
+	cbor OCTET STRING
+}
+*/
+
+var (
+	// OIDAttributeEvidence identifies the CSR attribute that carries
+	// EvidenceBundles (id-aa-evidence, draft-ietf-lamps-csr-attestation)
+	OIDAttributeEvidence = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 59}
+
+	// OIDEvidenceStatementCMW identifies an EvidenceStatement that carries a
+	// CMW (id-pe-cmw, draft-ietf-rats-msg-wrap).  EATs are wrapped in a CBOR
+	// CMW record so that their media type is preserved.
+	OIDEvidenceStatementCMW = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 35}
+)
+
+type csrAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type evidenceBundle struct {
+	Evidences []evidenceStatement
+	Certs     []asn1.RawValue `asn1:"optional"`
+}
+
+type evidenceStatement struct {
+	Type asn1.ObjectIdentifier
+	Stmt asn1.RawValue
+	Hint string `asn1:"utf8,optional"`
+}
+
+type certificationRequest struct {
+	TBS                asn1.RawValue
+	SignatureAlgorithm asn1.RawValue
+	Signature          asn1.BitString
+}
+
+type certificationRequestInfo struct {
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes asn1.RawValue
+}
+
+// SetCSRChallenge prepares the receiver Eat for inclusion in a certificate
+// signing request: the intended use is set to csr and the nonce to the
+// challenge issued by the registration authority
+func (e *Eat) SetCSRChallenge(challenge []byte) error {
+	var n Nonce
+	if err := n.Add(challenge); err != nil {
+		return fmt.Errorf("CSR challenge: %w", err)
+	}
+
+	use := IntendedUse(IntendedUseCSR)
+
+	e.Nonce = &n
+	e.IntendedUse = &use
+
+	return nil
+}
+
+// CreateCertificateRequestWithEAT creates a certificate signing request, as
+// x509.CreateCertificateRequest does, that carries the supplied encoded EAT of
+// the supplied media type in an evidence attribute.  The token must have
+// intended use csr and a nonce, and, if it has a cnf claim, the confirmation
+// key must match the public key of priv.
+func CreateCertificateRequestWithEAT(
+	rand io.Reader,
+	template *x509.CertificateRequest,
+	priv crypto.Signer,
+	mediaType string,
+	token []byte,
+) ([]byte, error) {
+	c, err := WrapEAT(mediaType, token)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := c.UnwrapEAT()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkCSREat(t.Eat, priv.Public(), nil); err != nil {
+		return nil, err
+	}
+
+	attr, err := newEvidenceAttribute(*c)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificateRequest(rand, template, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+
+	var req certificationRequest
+	if _, err := asn1.Unmarshal(der, &req); err != nil {
+		return nil, fmt.Errorf("decoding CSR: %w", err)
+	}
+
+	tbs, err := addCSRAttribute(req.TBS.FullBytes, attr)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signCSR(rand, priv, csr.SignatureAlgorithm, tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(certificationRequest{
+		TBS:                asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: req.SignatureAlgorithm,
+		Signature:          asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	})
+}
+
+func newEvidenceAttribute(c CMW) ([]byte, error) {
+	cmw, err := c.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := asn1.Marshal(cmw)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := asn1.Marshal([]evidenceBundle{
+		{
+			Evidences: []evidenceStatement{
+				{Type: OIDEvidenceStatementCMW, Stmt: asn1.RawValue{FullBytes: stmt}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(csrAttribute{
+		Type:   OIDAttributeEvidence,
+		Values: []asn1.RawValue{{FullBytes: bundles}},
+	})
+}
+
+// addCSRAttribute inserts the supplied attribute in the attributes of the
+// supplied CertificationRequestInfo, keeping the DER SET OF ordering
+func addCSRAttribute(tbs, attr []byte) ([]byte, error) {
+	var info certificationRequestInfo
+	if _, err := asn1.Unmarshal(tbs, &info); err != nil {
+		return nil, fmt.Errorf("decoding CSR info: %w", err)
+	}
+
+	attrs, err := splitDER(info.Attributes.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("decoding CSR attributes: %w", err)
+	}
+
+	attrs = append(attrs, attr)
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+
+	info.Attributes = asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      bytes.Join(attrs, nil),
+	}
+
+	return asn1.Marshal(info)
+}
+
+func splitDER(data []byte) ([][]byte, error) {
+	var items [][]byte
+
+	for len(data) > 0 {
+		var v asn1.RawValue
+
+		rest, err := asn1.Unmarshal(data, &v)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, v.FullBytes)
+		data = rest
+	}
+
+	return items, nil
+}
+
+func signCSR(rand io.Reader, priv crypto.Signer, alg x509.SignatureAlgorithm, tbs []byte) ([]byte, error) {
+	var opts crypto.SignerOpts
+
+	switch alg {
+	case x509.PureEd25519:
+		return priv.Sign(rand, tbs, crypto.Hash(0))
+	case x509.SHA256WithRSA, x509.ECDSAWithSHA256:
+		opts = crypto.SHA256
+	case x509.SHA384WithRSA, x509.ECDSAWithSHA384:
+		opts = crypto.SHA384
+	case x509.SHA512WithRSA, x509.ECDSAWithSHA512:
+		opts = crypto.SHA512
+	case x509.SHA256WithRSAPSS:
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	case x509.SHA384WithRSAPSS:
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA384}
+	case x509.SHA512WithRSAPSS:
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA512}
+	default:
+		return nil, fmt.Errorf("unsupported CSR signature algorithm %s", alg)
+	}
+
+	h := opts.HashFunc().New()
+	h.Write(tbs)
+
+	return priv.Sign(rand, h.Sum(nil), opts)
+}
+
+// ExtractEATsFromCSR returns the EATs carried in the evidence attribute of the
+// supplied certificate signing request.  The CSR signature is checked, but the
+// signatures of the tokens are NOT verified (see Parse).
+func ExtractEATsFromCSR(csr *x509.CertificateRequest) ([]*ParsedToken, error) {
+	records, err := extractCSREATRecords(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*ParsedToken, 0, len(records))
+
+	for _, r := range records {
+		t, err := CMW{Record: &r}.UnwrapEAT()
+		if err != nil {
+			return nil, fmt.Errorf("decoding EAT evidence: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+// extractCSREATRecords returns the CMW records wrapping EATs that are carried
+// in the evidence attribute of the supplied certificate signing request, after
+// checking the CSR signature
+func extractCSREATRecords(csr *x509.CertificateRequest) ([]CMWRecord, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("checking CSR signature: %w", err)
+	}
+
+	var info certificationRequestInfo
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &info); err != nil {
+		return nil, fmt.Errorf("decoding CSR info: %w", err)
+	}
+
+	attrs, err := splitDER(info.Attributes.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("decoding CSR attributes: %w", err)
+	}
+
+	var records []CMWRecord
+
+	for _, a := range attrs {
+		var attr csrAttribute
+		if _, err := asn1.Unmarshal(a, &attr); err != nil {
+			return nil, fmt.Errorf("decoding CSR attribute: %w", err)
+		}
+
+		if !attr.Type.Equal(OIDAttributeEvidence) {
+			continue
+		}
+
+		for _, v := range attr.Values {
+			rs, err := extractEATRecordsFromEvidenceBundles(v.FullBytes)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, rs...)
+		}
+	}
+
+	return records, nil
+}
+
+func extractEATRecordsFromEvidenceBundles(data []byte) ([]CMWRecord, error) {
+	var bundles []evidenceBundle
+	if _, err := asn1.Unmarshal(data, &bundles); err != nil {
+		return nil, fmt.Errorf("decoding evidence bundles: %w", err)
+	}
+
+	var records []CMWRecord
+
+	for _, b := range bundles {
+		for _, s := range b.Evidences {
+			if !s.Type.Equal(OIDEvidenceStatementCMW) {
+				continue
+			}
+
+			var c CMW
+
+			switch s.Stmt.Tag {
+			case asn1.TagOctetString:
+				var cmw []byte
+				if _, err := asn1.Unmarshal(s.Stmt.FullBytes, &cmw); err != nil {
+					return nil, fmt.Errorf("decoding CBOR CMW evidence: %w", err)
+				}
+				if err := c.UnmarshalCBOR(cmw); err != nil {
+					return nil, fmt.Errorf("decoding CBOR CMW evidence: %w", err)
+				}
+			case asn1.TagUTF8String:
+				var cmw string
+				if _, err := asn1.UnmarshalWithParams(s.Stmt.FullBytes, &cmw, "utf8"); err != nil {
+					return nil, fmt.Errorf("decoding JSON CMW evidence: %w", err)
+				}
+				if err := c.UnmarshalJSON([]byte(cmw)); err != nil {
+					return nil, fmt.Errorf("decoding JSON CMW evidence: %w", err)
+				}
+			default:
+				return nil, fmt.Errorf("unexpected ASN.1 tag %d for CMW evidence", s.Stmt.Tag)
+			}
+
+			if c.Record == nil {
+				continue
+			}
+
+			if _, err := ParseMediaType(c.Record.resolvedType()); err != nil {
+				// not an EAT
+				continue
+			}
+
+			records = append(records, *c.Record)
+		}
+	}
+
+	return records, nil
+}
+
+// VerifyCSREAT extracts the EAT carried in the supplied certificate signing
+// request and cross-checks it against the CSR: the token must have intended
+// use csr, one of its nonces must match the supplied challenge, and its cnf
+// claim must confirm the CSR public key.  Exactly one EAT must be present.
+// If resolver is not nil, the EAT must be a CWT or a JWT and its signature is
+// verified with the resolved key; otherwise, the signature of the token is NOT
+// verified.
+func VerifyCSREAT(
+	csr *x509.CertificateRequest, challenge []byte, resolver KeyResolver,
+) (*ParsedToken, error) {
+	records, err := extractCSREATRecords(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) != 1 {
+		return nil, fmt.Errorf("expecting exactly one EAT in CSR, found %d", len(records))
+	}
+
+	t, err := CMW{Record: &records[0]}.UnwrapEAT()
+	if err != nil {
+		return nil, fmt.Errorf("decoding EAT evidence: %w", err)
+	}
+
+	if resolver != nil {
+		var vt *VerifiedToken
+
+		switch t.Format {
+		case FormatCWT:
+			vt, err = Verify(records[0].Value, resolver)
+		case FormatJWT:
+			vt, err = VerifyJWT(string(records[0].Value), resolver)
+		default:
+			return nil, fmt.Errorf("expecting a CWT or JWT to verify, found %s", t.Format)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		t.Eat = vt.Eat
+	}
+
+	if err := checkCSREat(t.Eat, csr.PublicKey, challenge); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// checkCSREat checks that the supplied Eat has intended use csr, has a nonce
+// (matching challenge, if not nil), and that its cnf key, if present, matches
+// pub.  When challenge is not nil, the cnf claim is mandatory.
+func checkCSREat(e *Eat, pub crypto.PublicKey, challenge []byte) error {
+	if e == nil {
+		return errors.New("no claims-set in EAT")
+	}
+
+	if e.IntendedUse == nil || *e.IntendedUse != IntendedUseCSR {
+		return errors.New("EAT intended use is not csr")
+	}
+
+	if e.Nonce == nil {
+		return errors.New("EAT has no nonce")
+	}
+
+	if challenge != nil && !hasNonce(*e.Nonce, challenge) {
+		return errors.New("EAT nonce does not match the CSR challenge")
+	}
+
+	if e.Cnf == nil {
+		if challenge != nil {
+			return errors.New("EAT has no cnf claim")
+		}
+		return nil
+	}
+
+	if e.Cnf.Key == nil {
+		return errors.New("EAT cnf claim has no COSE key")
+	}
+
+	cnf, err := e.Cnf.Key.PublicKey()
+	if err != nil {
+		return fmt.Errorf("EAT cnf key: %w", err)
+	}
+
+	if !publicKeyEqual(cnf, pub) {
+		return errors.New("EAT cnf key does not match the CSR public key")
+	}
+
+	return nil
+}
+
+func hasNonce(ns Nonce, v []byte) bool {
+	for i := 0; i < ns.Len(); i++ {
+		if bytes.Equal(ns.GetI(i), v) {
+			return true
+		}
+	}
+	return false
+}
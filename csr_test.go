@@ -0,0 +1,132 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+var csrChallenge = []byte{
+	0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+	0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10,
+}
+
+func newCSREat(t *testing.T, pub *ecdsa.PublicKey) Eat {
+	e := Eat{UEID: &ueID}
+	require.Nil(t, e.SetCSRChallenge(csrChallenge))
+
+	if pub != nil {
+		ck, err := cose.NewKeyFromPublic(pub)
+		require.Nil(t, err)
+
+		x, _ := ck.Params[cose.KeyLabelEC2X].([]byte)
+		y, _ := ck.Params[cose.KeyLabelEC2Y].([]byte)
+
+		e.Cnf = &KeyConfirmation{
+			Key: &COSEKey{Type: cose.KeyTypeEC2, Crv: cose.CurveP256, X: x, Y: y},
+		}
+	}
+
+	return e
+}
+
+func newCSRWithEAT(t *testing.T, priv *ecdsa.PrivateKey, e Eat) *x509.CertificateRequest {
+	token, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	template := x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "device"},
+		DNSNames: []string{"device.example"},
+	}
+
+	der, err := CreateCertificateRequestWithEAT(rand.Reader, &template, priv, MediaTypeUCCS, token)
+	require.Nil(t, err)
+
+	csr, err := x509.ParseCertificateRequest(der)
+	require.Nil(t, err)
+
+	return csr
+}
+
+func TestCSR_VerifyCSREAT_ok(t *testing.T) {
+	priv := newTestP256Key(t)
+	e := newCSREat(t, &priv.PublicKey)
+
+	csr := newCSRWithEAT(t, priv, e)
+	assert.Equal(t, []string{"device.example"}, csr.DNSNames)
+
+	actual, err := VerifyCSREAT(csr, csrChallenge, nil)
+	require.Nil(t, err)
+	assert.Equal(t, FormatUCCS, actual.Format)
+	assert.Equal(t, e.UEID, actual.Eat.UEID)
+	assert.Equal(t, "csr", actual.Eat.IntendedUse.String())
+}
+
+func TestCSR_VerifyCSREAT_wrong_challenge(t *testing.T) {
+	priv := newTestP256Key(t)
+	csr := newCSRWithEAT(t, priv, newCSREat(t, &priv.PublicKey))
+
+	_, err := VerifyCSREAT(csr, []byte("a different challenge"), nil)
+	assert.EqualError(t, err, "EAT nonce does not match the CSR challenge")
+}
+
+func TestCSR_VerifyCSREAT_no_cnf(t *testing.T) {
+	priv := newTestP256Key(t)
+	csr := newCSRWithEAT(t, priv, newCSREat(t, nil))
+
+	_, err := VerifyCSREAT(csr, csrChallenge, nil)
+	assert.EqualError(t, err, "EAT has no cnf claim")
+}
+
+func TestCSR_VerifyCSREAT_no_eat(t *testing.T) {
+	priv := newTestP256Key(t)
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, priv)
+	require.Nil(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.Nil(t, err)
+
+	_, err = VerifyCSREAT(csr, csrChallenge, nil)
+	assert.EqualError(t, err, "expecting exactly one EAT in CSR, found 0")
+}
+
+func TestCSR_CreateCertificateRequestWithEAT_NG(t *testing.T) {
+	priv := newTestP256Key(t)
+
+	e := newCSREat(t, &newTestP256Key(t).PublicKey)
+	token, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	_, err = CreateCertificateRequestWithEAT(
+		rand.Reader, &x509.CertificateRequest{}, priv, MediaTypeUCCS, token,
+	)
+	assert.EqualError(t, err, "EAT cnf key does not match the CSR public key")
+
+	e = newCSREat(t, &priv.PublicKey)
+	e.IntendedUse = nil
+	token, err = e.ToCBOR()
+	require.Nil(t, err)
+
+	_, err = CreateCertificateRequestWithEAT(
+		rand.Reader, &x509.CertificateRequest{}, priv, MediaTypeUCCS, token,
+	)
+	assert.EqualError(t, err, "EAT intended use is not csr")
+}
+
+func TestIntendedUse_Validate(t *testing.T) {
+	for u := IntendedUse(IntendedUseGeneric); u <= IntendedUsePoP; u++ {
+		assert.Nil(t, u.Validate())
+	}
+
+	assert.EqualError(t, IntendedUse(0).Validate(), "out of range value 0 for IntendedUse type")
+	assert.EqualError(t, IntendedUse(6).Validate(), "out of range value 6 for IntendedUse type")
+}
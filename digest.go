@@ -0,0 +1,147 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/veraison/swid"
+)
+
+/*
+digest = [
+
+	alg: (int / text),
+	val: eat.binary-data
+
+]
+*/
+
+// Digest models the digest of a measured component.  Alg is an identifier
+// from the IANA Named Information Hash Algorithm Registry (e.g., swid.Sha256).
+//
+// The current measured-component draft allows the algorithm to be carried as
+// either an integer or a text string (the registry name, e.g., "sha-256").
+// Draft -05 used the CoSWID hash-entry instead, which is encoded as
+// [int, bstr] in CBOR and as "<alg-name>:<base64-value>" in JSON.  All these
+// forms are accepted on decoding; encoding always uses the integer form, which
+// is understood by both revisions.
+type Digest struct {
+	Alg   uint64
+	Value []byte
+}
+
+// Validate checks that the digest value has the length mandated by its hash
+// algorithm
+func (d Digest) Validate() error {
+	return swid.ValidHashEntry(d.Alg, d.Value)
+}
+
+// MarshalCBOR encodes the receiver Digest as a CBOR array [alg, val]
+func (d Digest) MarshalCBOR() ([]byte, error) {
+	return em.Marshal([]interface{}{d.Alg, d.Value})
+}
+
+// UnmarshalCBOR decodes a CBOR array [alg, val] where alg is either an
+// integer or a text string
+func (d *Digest) UnmarshalCBOR(data []byte) error {
+	var raw []cbor.RawMessage
+	if err := dm.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("CBOR decoding failed for digest: %w", err)
+	}
+
+	if len(raw) != 2 {
+		return fmt.Errorf("invalid digest CBOR array length: %d", len(raw))
+	}
+
+	if len(raw[0]) > 0 && isCBORTextString(raw[0]) {
+		var name string
+		if err := dm.Unmarshal(raw[0], &name); err != nil {
+			return err
+		}
+		alg, err := hashAlgorithmFromName(name)
+		if err != nil {
+			return err
+		}
+		d.Alg = alg
+	} else if err := dm.Unmarshal(raw[0], &d.Alg); err != nil {
+		return fmt.Errorf("invalid digest algorithm: %w", err)
+	}
+
+	if err := dm.Unmarshal(raw[1], &d.Value); err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the receiver Digest as a JSON array [alg, val] where val
+// is base64url encoded
+func (d Digest) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{
+		d.Alg,
+		base64.RawURLEncoding.EncodeToString(d.Value),
+	})
+}
+
+// UnmarshalJSON decodes either a JSON array [alg, val] or the legacy (draft
+// -05) hash-entry string "<alg-name>:<base64-value>"
+func (d *Digest) UnmarshalJSON(data []byte) error {
+	if !isJSONArray(data) {
+		var he swid.HashEntry
+		if err := he.UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("JSON decoding failed for digest: %w", err)
+		}
+		d.Alg = he.HashAlgID
+		d.Value = he.HashValue
+		return nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("JSON decoding failed for digest: %w", err)
+	}
+
+	if len(raw) != 2 {
+		return fmt.Errorf("invalid digest JSON array length: %d", len(raw))
+	}
+
+	var alg interface{}
+	if err := json.Unmarshal(raw[0], &alg); err != nil {
+		return err
+	}
+
+	switch t := alg.(type) {
+	case string:
+		id, err := hashAlgorithmFromName(t)
+		if err != nil {
+			return err
+		}
+		d.Alg = id
+	case float64:
+		if t < 0 || t != float64(uint64(t)) {
+			return fmt.Errorf("invalid digest algorithm: %v", t)
+		}
+		d.Alg = uint64(t)
+	default:
+		return fmt.Errorf("invalid digest algorithm type: %T", t)
+	}
+
+	var val string
+	if err := json.Unmarshal(raw[1], &val); err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(val, "="))
+	if err != nil {
+		return fmt.Errorf("invalid digest value: %w", err)
+	}
+	d.Value = value
+
+	return nil
+}
@@ -4,7 +4,14 @@
 package eat
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 // Eat is the internal representation of a EAT token
@@ -20,7 +27,7 @@ type Eat struct {
 	OemBoot         *bool     `cbor:"262,keyasint,omitempty" json:"oemboot,omitempty"`
 	DebugStatus     *Debug    `cbor:"263,keyasint,omitempty" json:"dbgstat,omitempty"`
 	Location        *Location `cbor:"264,keyasint,omitempty" json:"location,omitempty"`
-	Profile         *Profile  `cbor:"265,keyasint,omitempty" json:"eat-profile,omitempty"`
+	Profile         *Profile  `cbor:"265,keyasint,omitempty" json:"eat_profile,omitempty"`
 	Submods         *Submods  `cbor:"266,keyasint,omitempty" json:"submods,omitempty"`
 	BootCount       *uint     `cbor:"267,keyasint,omitempty" json:"bootcount,omitempty"`
 	BootSeed        *[]byte   `cbor:"268,keyasint,omitempty" json:"bootseed,omitempty"`
@@ -30,30 +37,438 @@ type Eat struct {
 	Manifests       *[]Manifest    `cbor:"272,keyasint,omitempty" json:"manifests,omitempty"`
 	Measurements    *[]Measurement `cbor:"273,keyasint,omitempty" json:"measurements,omitempty"`
 	// TODO: MeasrementResults
-	// TODO: IntendedUse
+	IntendedUse *IntendedUse `cbor:"275,keyasint,omitempty" json:"intuse,omitempty"`
 	CWTClaims
 }
 
-// FromCBOR deserializes the supplied CBOR encoded EAT into the receiver Eat
+// FromCBOR deserializes the supplied CBOR encoded EAT into the receiver Eat.
+// If the eat_profile claim identifies a registered profile, the decoded claims
+// are checked against it.
 func (e *Eat) FromCBOR(data []byte) error {
-	return dm.Unmarshal(data, e)
+	if err := dm.Unmarshal(data, e); err != nil {
+		return err
+	}
+
+	return e.validateProfile(EncodingCBOR)
 }
 
-// ToCBOR serializes the receiver Eat into CBOR encoded EAT
+// ToCBOR serializes the receiver Eat into CBOR encoded EAT.  A cnf claim, in
+// the Eat or in a nested submod, that would disclose a private or symmetric key
+// is refused.
 //
 //nolint:gocritic
 func (e Eat) ToCBOR() ([]byte, error) {
+	if err := e.validateCnf(); err != nil {
+		return nil, err
+	}
+
 	return em.Marshal(e)
 }
 
-// FromJSON deserializes the supplied JSON encoded EAT into the receiver Eat
+// FromJSON deserializes the supplied JSON encoded EAT into the receiver Eat.
+// If the eat_profile claim identifies a registered profile, the decoded claims
+// are checked against it.
 func (e *Eat) FromJSON(data []byte) error {
-	return json.Unmarshal(data, e)
+	if err := json.Unmarshal(data, e); err != nil {
+		return err
+	}
+
+	return e.validateProfile(EncodingJSON)
 }
 
-// ToJSON serializes the receiver Eat into JSON encoded EAT
+// ToJSON serializes the receiver Eat into JSON encoded EAT.  cnf claims are
+// checked as in ToCBOR.
 //
 //nolint:gocritic
 func (e Eat) ToJSON() ([]byte, error) {
+	if err := e.validateCnf(); err != nil {
+		return nil, err
+	}
+
 	return json.Marshal(e)
 }
+
+// Validate checks that the claims in the receiver Eat, including those of
+// nested submods, are well-formed.  If the eat_profile claim identifies a
+// registered profile, the claims are also checked against its rules.
+//
+//nolint:gocritic
+func (e Eat) Validate() error {
+	var errs []error
+
+	check := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if e.Nonce != nil {
+		check("eat_nonce", e.Nonce.Validate())
+	}
+	if e.UEID != nil {
+		check("ueid", e.UEID.Validate())
+	}
+	if e.HardwareVersion != nil {
+		check("hwversion", e.HardwareVersion.Validate())
+	}
+	if e.DebugStatus != nil {
+		check("dbgstat", e.DebugStatus.Validate())
+	}
+	if e.Location != nil {
+		check("location", e.Location.Validate())
+	}
+	if e.Profile != nil {
+		_, err := e.Profile.Get()
+		check("eat_profile", err)
+	}
+	if e.SoftwareVersion != nil {
+		check("swversion", e.SoftwareVersion.Validate())
+	}
+	if e.IntendedUse != nil {
+		check("intuse", e.IntendedUse.Validate())
+	}
+	if e.Cnf != nil {
+		check("cnf", e.Cnf.Validate())
+	}
+	e.eachSubmod(func(name string, value interface{}) {
+		switch t := value.(type) {
+		case Eat:
+			check("submod "+name, t.Validate())
+		case []byte:
+			check("submod "+name, checkTags(t))
+		}
+	})
+
+	if err := e.validateProfile(0); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// eachSubmod calls fn with the name and value of each submod of the receiver
+// Eat, in name order
+//
+//nolint:gocritic
+func (e Eat) eachSubmod(fn func(name string, value interface{})) {
+	if e.Submods == nil {
+		return
+	}
+
+	names := make([]string, 0, len(*e.Submods))
+	for name := range *e.Submods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fn(name, (*e.Submods)[name].value)
+	}
+}
+
+// validateCnf checks the cnf claims of the receiver Eat and of its nested Eat
+// submods (see KeyConfirmation.Validate), so that no private or symmetric key
+// is disclosed when the Eat is serialized
+//
+//nolint:gocritic
+func (e Eat) validateCnf() error {
+	var errs []error
+
+	if e.Cnf != nil {
+		if err := e.Cnf.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("cnf: %w", err))
+		}
+	}
+
+	e.eachSubmod(func(name string, value interface{}) {
+		if sub, ok := value.(Eat); ok {
+			if err := sub.validateCnf(); err != nil {
+				errs = append(errs, fmt.Errorf("submod %s: %w", name, err))
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// eatAlias has the same fields as Eat but none of its methods, which allows
+// the JSON deserializer below to fall back to the default behavior
+type eatAlias Eat
+
+// eatJSON overrides the JSON deserialization of the Eat byte string claims,
+// which RFC 9711 requires to be base64url encoded without padding
+type eatJSON struct {
+	*eatAlias
+	OemID         *binaryData `json:"oemid,omitempty"`
+	HardwareModel *binaryData `json:"hwmodel,omitempty"`
+	BootSeed      *binaryData `json:"bootseed,omitempty"`
+	CwtID         *binaryData `json:"cti,omitempty"`
+	// accepted on decoding for compatibility with earlier releases
+	LegacyProfile *Profile `json:"eat-profile,omitempty"`
+}
+
+// MarshalJSON serializes the receiver Eat using the RFC 9711 JSON claim names
+// and encodings.  Claims are emitted in the order of the Eat fields.
+//
+//nolint:gocritic
+func (e Eat) MarshalJSON() ([]byte, error) {
+	return e.marshalJSON(false)
+}
+
+// UnmarshalJSON deserializes the supplied JSON data into the receiver Eat.
+// Byte strings may be encoded using either base64url or standard base64, and
+// the profile claim is also accepted under its pre-RFC name "eat-profile".
+func (e *Eat) UnmarshalJSON(data []byte) error {
+	aux := eatJSON{eatAlias: (*eatAlias)(e)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	e.OemID = (*[]byte)(aux.OemID)
+	e.HardwareModel = (*[]byte)(aux.HardwareModel)
+	e.BootSeed = (*[]byte)(aux.BootSeed)
+	e.CwtID = (*[]byte)(aux.CwtID)
+
+	if e.Profile == nil {
+		e.Profile = aux.LegacyProfile
+	}
+
+	return nil
+}
+
+// JSONOptions controls the JSON serialization of an Eat
+type JSONOptions struct {
+	// Legacy reproduces the output of releases of this package that predate
+	// RFC 9711 conformance: the profile claim is named "eat-profile" and byte
+	// strings (including those in nested submods, measurements and manifests)
+	// are encoded as padded standard base64.
+	Legacy bool
+}
+
+// ToJSONWithOptions serializes the receiver Eat into JSON encoded EAT according
+// to the supplied options
+//
+//nolint:gocritic
+func (e Eat) ToJSONWithOptions(opts JSONOptions) ([]byte, error) {
+	if !opts.Legacy {
+		return e.ToJSON()
+	}
+
+	if err := e.validateCnf(); err != nil {
+		return nil, err
+	}
+
+	data, err := e.marshalJSON(true)
+	if err != nil {
+		return nil, fmt.Errorf("legacy JSON encoding failed: %w", err)
+	}
+
+	return data, nil
+}
+
+// marshalJSON encodes the claims of the receiver Eat in the order of its
+// fields, those of CWTClaims last, with byte strings encoded as base64url or,
+// in legacy mode, as padded standard base64
+//
+//nolint:gocritic
+func (e Eat) marshalJSON(legacy bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	err := forEachJSONClaim(reflect.ValueOf(e), func(name string, v interface{}) error {
+		value, err := marshalJSONClaim(v, legacy)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if legacy && name == "eat_profile" {
+			name = "eat-profile"
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// forEachJSONClaim calls fn with the JSON name and value of each non-nil
+// pointer field of the supplied struct, including those of embedded structs,
+// in field order
+func forEachJSONClaim(v reflect.Value, fn func(name string, v interface{}) error) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+
+		if f.Anonymous {
+			if err := forEachJSONClaim(fv, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "" || name == "-" || fv.Kind() != reflect.Ptr || fv.IsNil() {
+			continue
+		}
+
+		if err := fn(name, fv.Elem().Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyFormat is the pre-RFC 9711 JSON encoding of Measurement and Manifest
+type legacyFormat struct {
+	Type   int
+	Format []byte
+}
+
+func marshalJSONClaim(v interface{}, legacy bool) ([]byte, error) {
+	switch t := v.(type) {
+	case []byte:
+		if legacy {
+			return json.Marshal(t)
+		}
+		return json.Marshal(binaryData(t))
+	case []Measurement:
+		if legacy {
+			l := make([]legacyFormat, len(t))
+			for i, m := range t {
+				l[i] = legacyFormat{m.Type, m.Format}
+			}
+			return json.Marshal(l)
+		}
+	case []Manifest:
+		if legacy {
+			l := make([]legacyFormat, len(t))
+			for i, m := range t {
+				l[i] = legacyFormat{m.Type, m.Format}
+			}
+			return json.Marshal(l)
+		}
+	case Submods:
+		if legacy {
+			return marshalLegacySubmods(t)
+		}
+	case Nonce, UEID:
+		if legacy {
+			return marshalLegacyBase64(t)
+		}
+	}
+
+	return json.Marshal(v)
+}
+
+func marshalLegacySubmods(submods Submods) ([]byte, error) {
+	names := make([]string, 0, len(submods))
+	for name := range submods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, name := range names {
+		var (
+			value []byte
+			err   error
+		)
+
+		switch t := submods[name].value.(type) {
+		case Eat:
+			value, err = t.marshalJSON(true)
+		default:
+			value, err = json.Marshal(t)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("submod %s: %w", name, err)
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// marshalLegacyBase64 encodes the supplied value, whose JSON encoding is a
+// base64url string or an array thereof, using padded standard base64
+func marshalLegacyBase64(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var claim interface{}
+	if err := json.Unmarshal(data, &claim); err != nil {
+		return nil, err
+	}
+
+	lv, err := toLegacyBase64(claim)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(lv)
+}
+
+// toLegacyBase64 converts a base64url string, or an array thereof, to padded
+// standard base64
+func toLegacyBase64(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case string:
+		b, err := decodeBinaryData(t)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case []interface{}:
+		r := make([]interface{}, len(t))
+		for i := range t {
+			lv, err := toLegacyBase64(t[i])
+			if err != nil {
+				return nil, err
+			}
+			r[i] = lv
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unexpected type %T", t)
+	}
+}
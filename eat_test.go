@@ -4,10 +4,14 @@
 package eat
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -75,6 +79,25 @@ func cborRoundTripper(t *testing.T, tv Eat, expected []byte) {
 	assert.Equal(t, tv, actual)
 }
 
+// newTestProfileEat returns a claims-set with a nonce and a UEID that declares
+// the supplied profile
+func newTestProfileEat(t *testing.T, profileID string) Eat {
+	profile, err := NewProfile(profileID)
+	require.Nil(t, err)
+
+	return Eat{
+		Profile: profile,
+		Nonce:   &Nonce{nonce{nonceBytes}},
+		UEID:    &ueID,
+	}
+}
+
+func newTestP256Key(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	return key
+}
+
 func jsonRoundTripper(t *testing.T, tv Eat, expected string) {
 	data, err := tv.ToJSON()
 
@@ -157,9 +180,41 @@ func TestEat_Full_RoundtripCBOR(t *testing.T) {
 
 func TestEat_Full_RoundtripJSON(t *testing.T) {
 	tv := fatEat
+	expected := `
+{
+	"eat_nonce": "AAAAAAAAAAA",
+	"oemid": "________",
+	"oemboot": true,
+	"dbgstat": 1,
+	"location": {
+		"lat": 12.34,
+		"long": 56.78
+	},
+	"ueid": "Ad6tvu_erb7v3q2-796tvu8",
+	"uptime": 60,
+	"iss": "Acme Inc.",
+	"sub": "rr-trap",
+	"aud": "Acme Inc.",
+	"exp": 0,
+	"nbf": 0,
+	"iat": 0,
+	"cti": "________"
+}`
+	// NOTE: cti is not in JSON EAT though
+	jsonRoundTripper(t, tv, expected)
+}
+
+func TestEat_Full_LegacyJSON(t *testing.T) {
+	tv := fatEat
+
+	profile, err := NewProfile("http://arm.com/psa/2.0.0")
+	require.Nil(t, err)
+	tv.Profile = profile
+
 	expected := `
 {
 	"eat_nonce": "AAAAAAAAAAA=",
+	"eat-profile": "http://arm.com/psa/2.0.0",
 	"oemid": "////////",
 	"oemboot": true,
 	"dbgstat": 1,
@@ -177,8 +232,44 @@ func TestEat_Full_RoundtripJSON(t *testing.T) {
 	"iat": 0,
 	"cti": "////////"
 }`
-	// NOTE: cti is not in JSON EAT though
-	jsonRoundTripper(t, tv, expected)
+
+	data, err := tv.ToJSONWithOptions(JSONOptions{Legacy: true})
+	require.Nil(t, err)
+	assert.JSONEq(t, expected, string(data))
+
+	// legacy output is still accepted on decoding
+	actual := Eat{}
+	require.Nil(t, actual.FromJSON(data))
+	assert.Equal(t, tv, actual)
+
+	data, err = tv.ToJSONWithOptions(JSONOptions{})
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"eat_profile":"http://arm.com/psa/2.0.0"`)
+}
+
+func TestEat_Submods_LegacyJSON(t *testing.T) {
+	var inner Submods
+	require.Nil(t, inner.Add("xyz", []byte{0xd8, 0x3d, 0xd2, 0x41, 0xa0}))
+
+	var outer Submods
+	require.Nil(t, outer.Add("0", Eat{Nonce: &Nonce{nonce{nonceBytes}}, Submods: &inner}))
+
+	tv := Eat{Submods: &outer}
+
+	expected := `{
+		"submods": {
+			"0": {
+				"eat_nonce": "AAAAAAAAAAA=",
+				"submods": {
+					"xyz": "2D3SQaA="
+				}
+			}
+		}
+	}`
+
+	data, err := tv.ToJSONWithOptions(JSONOptions{Legacy: true})
+	require.Nil(t, err)
+	assert.JSONEq(t, expected, string(data))
 }
 
 func TestEat_Submods_RoundtripJSON(t *testing.T) {
@@ -186,9 +277,74 @@ func TestEat_Submods_RoundtripJSON(t *testing.T) {
 	expected := `{
 		"submods": {
 		  "eat-claims": {},
-		  "eat-token": "2D3SQaA="
+		  "eat-token": "2D3SQaA"
 		}
 	  }`
 
 	jsonRoundTripper(t, tv, expected)
 }
+
+func newTestBinaryClaimsEat(t *testing.T) Eat {
+	var n Nonce
+	require.Nil(t, n.Add([]byte{0x00, 0x01, 0x02, 0x03, 0xfb, 0xff, 0xfe, 0x3e}))
+
+	oemID := []byte{0xff, 0xfb, 0xfe}
+	bootSeed := []byte{0xfb, 0xef, 0xbe, 0xff, 0xff}
+	cti := []byte{0xfc}
+	uptime := uint(5)
+
+	profile, err := NewProfile("http://arm.com/psa/2.0.0")
+	require.Nil(t, err)
+
+	var submods Submods
+	require.Nil(t, submods.Add("a", Eat{OemID: &oemID}))
+	require.Nil(t, submods.Add("tok", []byte{0xd8, 0x3d, 0xd2, 0x41, 0xa0, 0xff}))
+
+	return Eat{
+		Nonce:        &n,
+		OemID:        &oemID,
+		Uptime:       &uptime,
+		Profile:      profile,
+		Submods:      &submods,
+		BootSeed:     &bootSeed,
+		Manifests:    &[]Manifest{{Type: 258, Format: []byte{0xfb, 0xff}}},
+		Measurements: &[]Measurement{{Type: 258, Format: []byte{0xff, 0xfe}}},
+		CWTClaims:    CWTClaims{CwtID: &cti},
+	}
+}
+
+func TestEat_BinaryClaims_RoundtripJSON(t *testing.T) {
+	tv := newTestBinaryClaimsEat(t)
+
+	// claims are emitted in the order of the Eat fields
+	expected := `{"eat_nonce":"AAECA_v__j4","oemid":"__v-","uptime":5,` +
+		`"eat_profile":"http://arm.com/psa/2.0.0","submods":{"a":{"oemid":"__v-"},"tok":"2D3SQaD_"},` +
+		`"bootseed":"----__8","manifests":[{"Type":258,"Format":"-_8"}],` +
+		`"measurements":[{"Type":258,"Format":"__4"}],"cti":"_A"}`
+
+	data, err := tv.ToJSON()
+	require.Nil(t, err)
+	assert.Equal(t, expected, string(data))
+
+	actual := Eat{}
+	require.Nil(t, actual.FromJSON(data))
+	assert.Equal(t, tv, actual)
+}
+
+func TestEat_BinaryClaims_LegacyJSON(t *testing.T) {
+	tv := newTestBinaryClaimsEat(t)
+
+	// byte for byte the output of releases that predate RFC 9711 conformance
+	expected := `{"eat_nonce":"AAECA/v//j4=","oemid":"//v+","uptime":5,` +
+		`"eat-profile":"http://arm.com/psa/2.0.0","submods":{"a":{"oemid":"//v+"},"tok":"2D3SQaD/"},` +
+		`"bootseed":"++++//8=","manifests":[{"Type":258,"Format":"+/8="}],` +
+		`"measurements":[{"Type":258,"Format":"//4="}],"cti":"/A=="}`
+
+	data, err := tv.ToJSONWithOptions(JSONOptions{Legacy: true})
+	require.Nil(t, err)
+	assert.Equal(t, expected, string(data))
+
+	actual := Eat{}
+	require.Nil(t, actual.FromJSON(data))
+	assert.Equal(t, tv, actual)
+}
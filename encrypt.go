@@ -0,0 +1,612 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	cose "github.com/veraison/go-cose"
+	"golang.org/x/crypto/hkdf"
+)
+
+// COSE content encryption and key distribution algorithms (RFC 9053)
+const (
+	AlgorithmA128GCM      cose.Algorithm = 1
+	AlgorithmA192GCM      cose.Algorithm = 2
+	AlgorithmA256GCM      cose.Algorithm = 3
+	AlgorithmA128KW       cose.Algorithm = -3
+	AlgorithmECDHESA128KW cose.Algorithm = -29
+)
+
+// maxEncryptionLayers bounds the nesting of encrypted tokens unwrapped by
+// DecryptAndVerify
+const maxEncryptionLayers = 4
+
+/*
+COSE_Encrypt0 = [
+  Headers,
+  ciphertext : bstr / nil,
+]
+
+COSE_Encrypt = [
+  Headers,
+  ciphertext : bstr / nil,
+  recipients : [+COSE_recipient]
+]
+
+COSE_recipient = [
+  Headers,
+  ciphertext : bstr / nil,
+  ? recipients : [+COSE_recipient]
+]
+*/
+
+// coseEncHeader holds the header parameters used by the supported encryption
+// algorithms
+type coseEncHeader struct {
+	Alg          cose.Algorithm `cbor:"1,keyasint,omitempty"`
+	Kid          []byte         `cbor:"4,keyasint,omitempty"`
+	IV           []byte         `cbor:"5,keyasint,omitempty"`
+	EphemeralKey *COSEKey       `cbor:"-1,keyasint,omitempty"`
+}
+
+type coseEncrypt0 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected coseEncHeader
+	Ciphertext  []byte
+}
+
+type coseRecipient struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected coseEncHeader
+	Ciphertext  []byte
+}
+
+type coseEncrypt struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected coseEncHeader
+	Ciphertext  []byte
+	Recipients  []coseRecipient
+}
+
+// EncryptCOSE wraps the supplied token, typically a signed CWT, in an
+// encrypted CWT.  If key is a []byte, it is used directly as the AES-GCM
+// content key (16, 24 or 32 bytes) of a COSE_Encrypt0 message.  If key is an
+// *ecdsa.PublicKey, the token is encrypted with a random A128GCM content key,
+// which is distributed to the key holder in a COSE_Encrypt message using
+// ECDH-ES+A128KW.  The optional kid identifies the key to the recipient.
+func EncryptCOSE(rand io.Reader, token []byte, key interface{}, kid []byte) ([]byte, error) {
+	var (
+		msg interface{}
+		tag uint64
+		err error
+	)
+
+	switch t := key.(type) {
+	case []byte:
+		tag = cborTagCOSEEncrypt0
+		msg, err = newCOSEEncrypt0(rand, token, t, kid)
+	case *ecdsa.PublicKey:
+		tag = cborTagCOSEEncrypt
+		msg, err = newCOSEEncrypt(rand, token, t, kid)
+	default:
+		return nil, fmt.Errorf("unsupported encryption key type %T", key)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := em.Marshal(cbor.Tag{Number: tag, Content: msg})
+	if err != nil {
+		return nil, err
+	}
+
+	return em.Marshal(cbor.RawTag{Number: cborTagCWT, Content: data})
+}
+
+func newCOSEEncrypt0(rand io.Reader, token, key, kid []byte) (*coseEncrypt0, error) {
+	alg, err := aesGCMAlgorithm(len(key))
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := em.Marshal(coseEncHeader{Alg: alg})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := coseEncrypt0{
+		Protected:   protected,
+		Unprotected: coseEncHeader{Kid: kid},
+	}
+
+	msg.Unprotected.IV, msg.Ciphertext, err = sealCOSE(rand, "Encrypt0", protected, key, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func newCOSEEncrypt(rand io.Reader, token []byte, pub *ecdsa.PublicKey, kid []byte) (*coseEncrypt, error) {
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(rand, cek); err != nil {
+		return nil, err
+	}
+
+	protected, err := em.Marshal(coseEncHeader{Alg: AlgorithmA128GCM})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := coseEncrypt{Protected: protected}
+
+	msg.Unprotected.IV, msg.Ciphertext, err = sealCOSE(rand, "Encrypt", protected, cek, token)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newECDHESRecipient(rand, cek, pub, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Recipients = []coseRecipient{*r}
+
+	return &msg, nil
+}
+
+func newECDHESRecipient(rand io.Reader, cek []byte, pub *ecdsa.PublicKey, kid []byte) (*coseRecipient, error) {
+	epk, secret, err := ecdhEphemeral(rand, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := em.Marshal(coseEncHeader{Alg: AlgorithmECDHESA128KW})
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := coseECDHKEK(secret, protected)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := aesKeyWrap(kek, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coseRecipient{
+		Protected:   protected,
+		Unprotected: coseEncHeader{Kid: kid, EphemeralKey: epk},
+		Ciphertext:  wrapped,
+	}, nil
+}
+
+// ecdhEphemeral generates an ephemeral key on the curve of the supplied
+// recipient key, returning it with the shared secret
+func ecdhEphemeral(rand io.Reader, pub *ecdsa.PublicKey) (*COSEKey, []byte, error) {
+	recipient, err := pub.ECDH()
+	if err != nil {
+		return nil, nil, fmt.Errorf("recipient key: %w", err)
+	}
+
+	eph, err := ecdsa.GenerateKey(pub.Curve, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	epk, err := NewCOSEKeyFromPublic(&eph.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k, err := eph.ECDH()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := k.ECDH(recipient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key agreement: %w", err)
+	}
+
+	return epk, secret, nil
+}
+
+// ecdhStatic computes the shared secret between the supplied ephemeral key
+// and recipient private key
+func ecdhStatic(epk *COSEKey, priv *ecdsa.PrivateKey) ([]byte, error) {
+	if epk == nil {
+		return nil, errors.New("no ephemeral key")
+	}
+
+	if err := epk.Validate(); err != nil {
+		return nil, fmt.Errorf("ephemeral key: %w", err)
+	}
+
+	pub, err := epk.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("ephemeral key: %w", err)
+	}
+
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok || ecPub.Curve != priv.Curve {
+		return nil, errors.New("ephemeral key does not match the curve of the recipient key")
+	}
+
+	ephemeral, err := ecPub.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("ephemeral key: %w", err)
+	}
+
+	k, err := priv.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := k.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+
+	return secret, nil
+}
+
+// coseECDHKEK derives the A128KW key encryption key from the ECDH shared
+// secret using HKDF-SHA-256 (RFC 9053, Section 6.3)
+func coseECDHKEK(secret, protected []byte) ([]byte, error) {
+	noParty := []interface{}{nil, nil, nil}
+
+	info, err := em.Marshal([]interface{}{
+		AlgorithmA128KW,
+		noParty,
+		noParty,
+		[]interface{}{128, protected},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), kek); err != nil {
+		return nil, err
+	}
+
+	return kek, nil
+}
+
+func sealCOSE(rand io.Reader, context string, protected, key, plaintext []byte) ([]byte, []byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, nil, err
+	}
+
+	aad, err := encStructure(context, protected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return iv, aead.Seal(nil, iv, plaintext, aad), nil
+}
+
+func openCOSE(context string, protected []byte, h coseEncHeader, key, ciphertext []byte) ([]byte, error) {
+	var p coseEncHeader
+	if err := dm.Unmarshal(protected, &p); err != nil {
+		return nil, fmt.Errorf("decoding protected header: %w", err)
+	}
+
+	alg, err := aesGCMAlgorithm(len(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Alg != alg {
+		return nil, fmt.Errorf("unsupported content encryption algorithm %s for %d bytes key", p.Alg, len(key))
+	}
+
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(h.IV) != aead.NonceSize() {
+		return nil, fmt.Errorf("expecting %d bytes IV, got %d", aead.NonceSize(), len(h.IV))
+	}
+
+	aad, err := encStructure(context, protected)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, h.IV, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+/*
+Enc_structure = [
+  context : "Encrypt" / "Encrypt0" / "Enc_Recipient" /
+      "Mac_Recipient" / "Rec_Recipient",
+  protected : empty_or_serialized_map,
+  external_aad : bstr
+]
+*/
+
+func encStructure(context string, protected []byte) ([]byte, error) {
+	return em.Marshal([]interface{}{context, protected, []byte{}})
+}
+
+func aesGCMAlgorithm(keySize int) (cose.Algorithm, error) {
+	switch keySize {
+	case 16:
+		return AlgorithmA128GCM, nil
+	case 24:
+		return AlgorithmA192GCM, nil
+	case 32:
+		return AlgorithmA256GCM, nil
+	default:
+		return 0, fmt.Errorf("invalid AES key size %d", keySize)
+	}
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Decrypt decrypts the supplied encrypted token, either a COSE_Encrypt0 or
+// COSE_Encrypt message (optionally wrapped in a CWT tag) or a JWE in compact
+// serialization, and returns the wrapped token.  The key is a []byte for
+// direct AES-GCM encryption, or an *ecdsa.PrivateKey for ECDH-ES+A128KW.
+func Decrypt(data []byte, key interface{}) ([]byte, error) {
+	if isJWE(data) {
+		token, err := decryptJWE(string(data), key)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(token), nil
+	}
+
+	tag, err := encryptedCOSETag(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag.Number {
+	case cborTagCOSEEncrypt0:
+		k, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("COSE_Encrypt0 needs a symmetric key, got %T", key)
+		}
+
+		var msg coseEncrypt0
+		if err := dm.Unmarshal(tag.Content, &msg); err != nil {
+			return nil, fmt.Errorf("decoding COSE_Encrypt0: %w", err)
+		}
+
+		return openCOSE("Encrypt0", msg.Protected, msg.Unprotected, k, msg.Ciphertext)
+	default:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("COSE_Encrypt needs an ECDSA private key, got %T", key)
+		}
+
+		var msg coseEncrypt
+		if err := dm.Unmarshal(tag.Content, &msg); err != nil {
+			return nil, fmt.Errorf("decoding COSE_Encrypt: %w", err)
+		}
+
+		cek, err := unwrapECDHESRecipients(msg.Recipients, k)
+		if err != nil {
+			return nil, err
+		}
+
+		return openCOSE("Encrypt", msg.Protected, msg.Unprotected, cek, msg.Ciphertext)
+	}
+}
+
+// encryptedCOSETag decodes the COSE_Encrypt0 or COSE_Encrypt tag of the
+// supplied message, skipping the CWT tag if present
+func encryptedCOSETag(data []byte) (*cbor.RawTag, error) {
+	if !isCBORTag(data) {
+		return nil, errors.New("expecting a tagged COSE_Encrypt0 or COSE_Encrypt message")
+	}
+
+	var tag cbor.RawTag
+	if err := dm.Unmarshal(data, &tag); err != nil {
+		return nil, fmt.Errorf("malformed COSE message: %w", err)
+	}
+
+	if tag.Number == cborTagCWT {
+		if !isCBORTag(tag.Content) {
+			return nil, errors.New("expecting a tagged COSE_Encrypt0 or COSE_Encrypt message")
+		}
+
+		if err := dm.Unmarshal(tag.Content, &tag); err != nil {
+			return nil, fmt.Errorf("malformed COSE message: %w", err)
+		}
+	}
+
+	switch tag.Number {
+	case cborTagCOSEEncrypt0, cborTagCOSEEncrypt:
+		return &tag, nil
+	default:
+		return nil, fmt.Errorf("expecting a COSE_Encrypt0 or COSE_Encrypt message, got tag %d", tag.Number)
+	}
+}
+
+func isEncrypted(data []byte) bool {
+	if isJWE(data) {
+		return true
+	}
+	_, err := encryptedCOSETag(data)
+	return err == nil
+}
+
+// unwrapECDHESRecipients returns the content key from the first recipient that
+// it can be unwrapped from with the supplied key
+func unwrapECDHESRecipients(recipients []coseRecipient, priv *ecdsa.PrivateKey) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+
+	var err error
+
+	for i, r := range recipients {
+		var cek []byte
+		if cek, err = unwrapECDHESRecipient(r, priv); err == nil {
+			return cek, nil
+		}
+		err = fmt.Errorf("recipient at index %d: %w", i, err)
+	}
+
+	return nil, err
+}
+
+func unwrapECDHESRecipient(r coseRecipient, priv *ecdsa.PrivateKey) ([]byte, error) {
+	var p coseEncHeader
+	if err := dm.Unmarshal(r.Protected, &p); err != nil {
+		return nil, fmt.Errorf("decoding protected header: %w", err)
+	}
+
+	if p.Alg != AlgorithmECDHESA128KW {
+		return nil, fmt.Errorf("unsupported key distribution algorithm %s", p.Alg)
+	}
+
+	secret, err := ecdhStatic(r.Unprotected.EphemeralKey, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := coseECDHKEK(secret, r.Protected)
+	if err != nil {
+		return nil, err
+	}
+
+	return aesKeyUnwrap(kek, r.Ciphertext)
+}
+
+// DecryptAndVerify decrypts the supplied encrypted token with the supplied key
+// (see Decrypt), unwrapping nested encryption layers, and verifies the signed
+// token found inside, either a COSE_Sign1 or a JWT, with the key found by the
+// supplied resolver
+func DecryptAndVerify(data []byte, key interface{}, resolver KeyResolver) (*VerifiedToken, error) {
+	if !isEncrypted(data) {
+		return nil, errors.New("token is not encrypted")
+	}
+
+	for i := 0; isEncrypted(data); i++ {
+		if i == maxEncryptionLayers {
+			return nil, fmt.Errorf("more than %d encryption layers", maxEncryptionLayers)
+		}
+
+		var err error
+		if data, err = Decrypt(data, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if isJWT(data) {
+		return VerifyJWT(string(data), resolver)
+	}
+
+	return Verify(data, resolver)
+}
+
+var aesKeyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyWrap implements the AES Key Wrap algorithm (RFC 3394)
+func aesKeyWrap(kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, fmt.Errorf("invalid key size %d for key wrap", len(key))
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(key) / 8
+	out := make([]byte, len(key)+8)
+	copy(out, aesKeyWrapIV)
+	copy(out[8:], key)
+
+	b := make([]byte, 16)
+
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[i*8:(i+1)*8])
+			block.Encrypt(b, b)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[i*8:], b[8:])
+		}
+	}
+
+	return out, nil
+}
+
+// aesKeyUnwrap implements the AES Key Unwrap algorithm (RFC 3394)
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("invalid wrapped key size %d", len(wrapped))
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+
+	b := make([]byte, 16)
+
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(b[8:], out[i*8:(i+1)*8])
+			block.Decrypt(b, b)
+
+			copy(out[:8], b[:8])
+			copy(out[i*8:], b[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(out[:8], aesKeyWrapIV) != 1 {
+		return nil, errors.New("key unwrap integrity check failed")
+	}
+
+	return out[8:], nil
+}
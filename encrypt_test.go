@@ -0,0 +1,167 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESKeyWrap_RFC3394(t *testing.T) {
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+	expected, _ := hex.DecodeString("1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5")
+
+	wrapped, err := aesKeyWrap(kek, key)
+	require.Nil(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := aesKeyUnwrap(kek, wrapped)
+	require.Nil(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 0x01
+	_, err = aesKeyUnwrap(kek, wrapped)
+	assert.EqualError(t, err, "key unwrap integrity check failed")
+}
+
+func TestEncryptCOSE_Encrypt0(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	signed, err := e.Sign(rand.Reader, signer, WithCWTTag())
+	require.Nil(t, err)
+
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	require.Nil(t, err)
+
+	encrypted, err := EncryptCOSE(rand.Reader, signed, key, []byte("kid-1"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xd8, 0x3d, 0xd0}, encrypted[:3])
+
+	plaintext, err := Decrypt(encrypted, key)
+	require.Nil(t, err)
+	assert.Equal(t, signed, plaintext)
+
+	actual, err := DecryptAndVerify(encrypted, key, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+
+	_, err = Decrypt(encrypted, make([]byte, 32))
+	assert.ErrorContains(t, err, "decrypting: ")
+
+	_, err = Decrypt(encrypted, make([]byte, 16))
+	assert.ErrorContains(t, err, "unsupported content encryption algorithm")
+}
+
+func TestEncryptCOSE_Encrypt(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	signed, err := e.Sign(rand.Reader, signer)
+	require.Nil(t, err)
+
+	key := newTestP256Key(t)
+
+	encrypted, err := EncryptCOSE(rand.Reader, signed, &key.PublicKey, []byte("kid-2"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xd8, 0x3d, 0xd8, 0x60}, encrypted[:4])
+
+	actual, err := DecryptAndVerify(encrypted, key, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, e.IssuedAt, actual.Eat.IssuedAt)
+
+	_, err = Decrypt(encrypted, newTestP256Key(t))
+	assert.EqualError(t, err, "recipient at index 0: key unwrap integrity check failed")
+
+	_, err = Decrypt(encrypted, []byte("not an ECDSA key"))
+	assert.EqualError(t, err, "COSE_Encrypt needs an ECDSA private key, got []uint8")
+}
+
+func TestEncryptCOSE_nested(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	signed, err := e.Sign(rand.Reader, signer, WithCWTTag())
+	require.Nil(t, err)
+
+	key := newTestP256Key(t)
+
+	inner, err := EncryptCOSE(rand.Reader, signed, &key.PublicKey, nil)
+	require.Nil(t, err)
+
+	outer, err := EncryptCOSE(rand.Reader, inner, &key.PublicKey, nil)
+	require.Nil(t, err)
+
+	actual, err := DecryptAndVerify(outer, key, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+
+	_, err = DecryptAndVerify(signed, key, resolver)
+	assert.EqualError(t, err, "token is not encrypted")
+}
+
+func TestEncryptCOSE_submod(t *testing.T) {
+	signer, _ := newTestSignerResolver(t)
+
+	signed, err := newTestSignedClaims(t).Sign(rand.Reader, signer, WithCWTTag())
+	require.Nil(t, err)
+
+	key := newTestP256Key(t)
+
+	encrypted, err := EncryptCOSE(rand.Reader, signed, &key.PublicKey, nil)
+	require.Nil(t, err)
+
+	var s Submods
+	require.Nil(t, s.Add("encrypted", encrypted))
+
+	e := Eat{Submods: &s}
+
+	data, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	var actual Eat
+	require.Nil(t, actual.FromCBOR(data))
+	assert.Equal(t, encrypted, (*actual.Submods).Get("encrypted"))
+}
+
+func TestEncryptJWE(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	jwt, err := e.SignJWT(rand.Reader, signer)
+	require.Nil(t, err)
+
+	ecKey := newTestP256Key(t)
+
+	for _, key := range []struct {
+		encrypt interface{}
+		decrypt interface{}
+	}{
+		{make([]byte, 16), make([]byte, 16)},
+		{&ecKey.PublicKey, ecKey},
+	} {
+		encrypted, err := EncryptJWE(rand.Reader, jwt, key.encrypt, "kid-3")
+		require.Nil(t, err)
+		assert.True(t, isJWE([]byte(encrypted)))
+
+		actual, err := DecryptAndVerify([]byte(encrypted), key.decrypt, resolver)
+		require.Nil(t, err)
+		assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+	}
+
+	encrypted, err := EncryptJWE(rand.Reader, jwt, &ecKey.PublicKey, "")
+	require.Nil(t, err)
+
+	_, err = Decrypt([]byte(encrypted), make([]byte, 16))
+	assert.EqualError(t, err, "ECDH-ES+A128KW needs an ECDSA private key, got []uint8")
+
+	_, err = Decrypt([]byte(encrypted), newTestP256Key(t))
+	assert.EqualError(t, err, "key unwrap integrity check failed")
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+// Geofence is implemented by geographic regions that can tell whether a
+// Location lies inside them
+type Geofence interface {
+	Contains(l Location) bool
+}
+
+// GeoCircle is a circular region described by its center and radius in metres
+type GeoCircle struct {
+	Center Location
+	Radius float64
+}
+
+// Contains returns true if the great-circle distance between the supplied
+// Location and the center of the receiver GeoCircle is at most its radius
+func (c GeoCircle) Contains(l Location) bool {
+	return c.Center.DistanceTo(l) <= c.Radius
+}
+
+// GeoPolygon is a region described by the ordered list of its vertices.  The
+// polygon is implicitly closed (the last vertex connects to the first one).
+// Edges are treated as straight lines in the latitude/longitude plane, which
+// is adequate for regions that are small compared to the Earth and do not
+// cross the antimeridian or include a pole.
+type GeoPolygon []Location
+
+// Contains returns true if the supplied Location is inside the receiver
+// GeoPolygon, using the even-odd (ray casting) rule.  Polygons with fewer than
+// three vertices contain no points.
+func (p GeoPolygon) Contains(l Location) bool {
+	if len(p) < 3 {
+		return false
+	}
+
+	x, y := float64(l.Longitude), float64(l.Latitude)
+	inside := false
+
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		xi, yi := float64(p[i].Longitude), float64(p[i].Latitude)
+		xj, yj := float64(p[j].Longitude), float64(p[j].Latitude)
+
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
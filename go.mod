@@ -1,6 +1,6 @@
 module github.com/veraison/eat
 
-go 1.23
+go 1.23.0
 
 toolchain go1.23.1
 
@@ -9,6 +9,8 @@ require (
 	github.com/stretchr/testify v1.11.1
 	github.com/veraison/go-cose v1.3.0
 	github.com/veraison/swid v1.1.0
+	github.com/x448/float16 v0.8.4
+	golang.org/x/crypto v0.41.0
 )
 
 require (
@@ -16,7 +18,7 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/sys v0.35.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
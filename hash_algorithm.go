@@ -0,0 +1,95 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+
+	"github.com/veraison/swid"
+	"golang.org/x/crypto/sha3"
+)
+
+// hashAlgorithms maps identifiers from the IANA Named Information Hash
+// Algorithm Registry to the constructor of the corresponding hash function.
+// Truncated SHA-256 variants are not included since they cannot be used to
+// produce new measurements.
+var (
+	hashAlgorithmsMu sync.RWMutex
+	hashAlgorithms   = map[uint64]func() hash.Hash{
+		swid.Sha256:   sha256.New,
+		swid.Sha384:   sha512.New384,
+		swid.Sha512:   sha512.New,
+		swid.Sha3_224: sha3.New224,
+		swid.Sha3_256: sha3.New256,
+		swid.Sha3_384: sha3.New384,
+		swid.Sha3_512: sha3.New512,
+	}
+)
+
+// RegisterHashAlgorithm associates the supplied Named Information hash
+// algorithm identifier with a hash function constructor, so that it can be
+// used to compute and verify measurements.  An error is returned if the
+// identifier is already registered.
+func RegisterHashAlgorithm(algID uint64, newHash func() hash.Hash) error {
+	if newHash == nil {
+		return fmt.Errorf("nil hash constructor for algorithm %d", algID)
+	}
+
+	hashAlgorithmsMu.Lock()
+	defer hashAlgorithmsMu.Unlock()
+
+	if _, ok := hashAlgorithms[algID]; ok {
+		return fmt.Errorf("hash algorithm %d already registered", algID)
+	}
+
+	hashAlgorithms[algID] = newHash
+
+	return nil
+}
+
+// newHash returns a fresh hash.Hash for the supplied Named Information hash
+// algorithm identifier
+func newHash(algID uint64) (hash.Hash, error) {
+	hashAlgorithmsMu.RLock()
+	newFn, ok := hashAlgorithms[algID]
+	hashAlgorithmsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm %d", algID)
+	}
+
+	return newFn(), nil
+}
+
+// hashAlgorithmNames maps the names in the IANA Named Information Hash
+// Algorithm Registry to their numeric identifiers
+var hashAlgorithmNames = map[string]uint64{
+	"sha-256":     swid.Sha256,
+	"sha-256-128": swid.Sha256_128,
+	"sha-256-120": swid.Sha256_120,
+	"sha-256-96":  swid.Sha256_96,
+	"sha-256-64":  swid.Sha256_64,
+	"sha-256-32":  swid.Sha256_32,
+	"sha-384":     swid.Sha384,
+	"sha-512":     swid.Sha512,
+	"sha3-224":    swid.Sha3_224,
+	"sha3-256":    swid.Sha3_256,
+	"sha3-384":    swid.Sha3_384,
+	"sha3-512":    swid.Sha3_512,
+}
+
+// hashAlgorithmFromName returns the Named Information identifier of the hash
+// algorithm with the supplied (case-insensitive) name
+func hashAlgorithmFromName(name string) (uint64, error) {
+	algID, ok := hashAlgorithmNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown hash algorithm %q", name)
+	}
+	return algID, nil
+}
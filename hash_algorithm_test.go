@@ -0,0 +1,26 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/veraison/swid"
+)
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	assert.EqualError(t,
+		RegisterHashAlgorithm(swid.Sha256, sha256.New),
+		"hash algorithm 1 already registered",
+	)
+	assert.EqualError(t,
+		RegisterHashAlgorithm(swid.Sha256_128, nil),
+		"nil hash constructor for algorithm 2",
+	)
+
+	_, err := newHash(swid.Sha256_96)
+	assert.EqualError(t, err, "unsupported hash algorithm 4")
+}
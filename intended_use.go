@@ -0,0 +1,68 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"fmt"
+)
+
+/*
+intended-use-type = &(
+    generic: 1,
+    registration: 2,
+    provisioning: 3,
+    csr: 4,
+    pop: 5
+)
+*/
+
+const (
+	// IntendedUseGeneric indicates that the token may be used for any purpose
+	IntendedUseGeneric = iota + 1
+
+	// IntendedUseRegistration indicates that the token is used to register the
+	// entity with a service
+	IntendedUseRegistration
+
+	// IntendedUseProvisioning indicates that the token is used to request the
+	// provisioning of keys or other secrets
+	IntendedUseProvisioning
+
+	// IntendedUseCSR indicates that the token is carried in a certificate
+	// signing request
+	IntendedUseCSR
+
+	// IntendedUsePoP indicates that the token is used as proof of possession
+	// of a key
+	IntendedUsePoP
+)
+
+// IntendedUse models the intended-use-type
+type IntendedUse uint
+
+// Validate makes sure that the receiver is a valid IntendedUse claim
+func (u IntendedUse) Validate() error {
+	if u < IntendedUseGeneric || u > IntendedUsePoP {
+		return fmt.Errorf("out of range value %v for IntendedUse type", uint(u))
+	}
+	return nil
+}
+
+// String returns the name of the receiver IntendedUse
+func (u IntendedUse) String() string {
+	switch u {
+	case IntendedUseGeneric:
+		return "generic"
+	case IntendedUseRegistration:
+		return "registration"
+	case IntendedUseProvisioning:
+		return "provisioning"
+	case IntendedUseCSR:
+		return "csr"
+	case IntendedUsePoP:
+		return "pop"
+	default:
+		return fmt.Sprintf("IntendedUse(%d)", uint(u))
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JWE key management algorithms (RFC 7518)
+const (
+	jweAlgDirect         = "dir"
+	jweAlgECDHESA128KW   = "ECDH-ES+A128KW"
+	jweContentTypeNested = "JWT"
+)
+
+var jweEncAlgorithms = map[int]string{
+	16: "A128GCM",
+	24: "A192GCM",
+	32: "A256GCM",
+}
+
+type jweHeader struct {
+	Alg string   `json:"alg"`
+	Enc string   `json:"enc"`
+	Kid string   `json:"kid,omitempty"`
+	Cty string   `json:"cty,omitempty"`
+	Epk *COSEKey `json:"epk,omitempty"`
+	Apu string   `json:"apu,omitempty"`
+	Apv string   `json:"apv,omitempty"`
+}
+
+// EncryptJWE wraps the supplied JWT in a JWE in compact serialization.  If key
+// is a []byte, it is used directly as the AES-GCM content key (16, 24 or 32
+// bytes).  If key is an *ecdsa.PublicKey, the JWT is encrypted with a random
+// A128GCM content key, which is distributed to the key holder using
+// ECDH-ES+A128KW.  The optional kid identifies the key to the recipient.
+func EncryptJWE(rand io.Reader, token string, key interface{}, kid string) (string, error) {
+	h := jweHeader{Kid: kid, Cty: jweContentTypeNested}
+
+	var cek, encryptedKey []byte
+
+	switch t := key.(type) {
+	case []byte:
+		enc, ok := jweEncAlgorithms[len(t)]
+		if !ok {
+			return "", fmt.Errorf("invalid AES key size %d", len(t))
+		}
+		h.Alg, h.Enc, cek = jweAlgDirect, enc, t
+	case *ecdsa.PublicKey:
+		h.Alg, h.Enc = jweAlgECDHESA128KW, jweEncAlgorithms[16]
+
+		cek = make([]byte, 16)
+		if _, err := io.ReadFull(rand, cek); err != nil {
+			return "", err
+		}
+
+		var err error
+		if h.Epk, encryptedKey, err = jweECDHESWrap(rand, cek, t); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported encryption key type %T", key)
+	}
+
+	header, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	protected := encodeBinaryData(header)
+
+	aead, err := newAESGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nil, iv, []byte(token), []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		encodeBinaryData(encryptedKey),
+		encodeBinaryData(iv),
+		encodeBinaryData(ciphertext),
+		encodeBinaryData(tag),
+	}, "."), nil
+}
+
+func jweECDHESWrap(rand io.Reader, cek []byte, pub *ecdsa.PublicKey) (*COSEKey, []byte, error) {
+	epk, secret, err := ecdhEphemeral(rand, pub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := aesKeyWrap(concatKDF(secret, jweAlgECDHESA128KW, nil, nil, 128), cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return epk, wrapped, nil
+}
+
+// concatKDF implements the Concat KDF with SHA-256 used by ECDH-ES (RFC 7518,
+// Section 4.6.2)
+func concatKDF(secret []byte, alg string, apu, apv []byte, keyDataLen int) []byte {
+	var otherInfo []byte
+	for _, v := range [][]byte{[]byte(alg), apu, apv} {
+		otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(len(v))) //nolint:gosec
+		otherInfo = append(otherInfo, v...)
+	}
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keyDataLen)) //nolint:gosec
+
+	var out []byte
+	for counter := uint32(1); len(out)*8 < keyDataLen; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter)
+		h.Write(secret)
+		h.Write(otherInfo)
+		out = h.Sum(out)
+	}
+
+	return out[:keyDataLen/8]
+}
+
+func isJWE(data []byte) bool {
+	return isJWT(data) && strings.Count(string(data), ".") == 4
+}
+
+func decryptJWE(token string, key interface{}) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return "", errors.New("malformed JWE")
+	}
+
+	var fields [4][]byte
+	for i := range fields {
+		var err error
+		if fields[i], err = decodeBinaryData(parts[i+1]); err != nil {
+			return "", fmt.Errorf("malformed JWE: %w", err)
+		}
+	}
+	encryptedKey, iv, ciphertext, tag := fields[0], fields[1], fields[2], fields[3]
+
+	data, err := decodeBinaryData(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("malformed JWE header: %w", err)
+	}
+
+	var h jweHeader
+	if err := json.Unmarshal(data, &h); err != nil {
+		return "", fmt.Errorf("malformed JWE header: %w", err)
+	}
+
+	var cek []byte
+
+	switch h.Alg {
+	case jweAlgDirect:
+		k, ok := key.([]byte)
+		if !ok {
+			return "", fmt.Errorf("%s needs a symmetric key, got %T", h.Alg, key)
+		}
+		if len(encryptedKey) != 0 {
+			return "", fmt.Errorf("unexpected encrypted key with %s", h.Alg)
+		}
+		cek = k
+	case jweAlgECDHESA128KW:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("%s needs an ECDSA private key, got %T", h.Alg, key)
+		}
+		if cek, err = jweECDHESUnwrap(h, k, encryptedKey); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported JWE key management algorithm %q", h.Alg)
+	}
+
+	if enc, ok := jweEncAlgorithms[len(cek)]; !ok || enc != h.Enc {
+		return "", fmt.Errorf("unsupported content encryption algorithm %q for %d bytes key", h.Enc, len(cek))
+	}
+
+	aead, err := newAESGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	if len(iv) != aead.NonceSize() {
+		return "", fmt.Errorf("expecting %d bytes IV, got %d", aead.NonceSize(), len(iv))
+	}
+
+	plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("decrypting: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+func jweECDHESUnwrap(h jweHeader, priv *ecdsa.PrivateKey, encryptedKey []byte) ([]byte, error) {
+	secret, err := ecdhStatic(h.Epk, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	apu, err := decodeBinaryData(h.Apu)
+	if err != nil {
+		return nil, fmt.Errorf("malformed apu: %w", err)
+	}
+
+	apv, err := decodeBinaryData(h.Apv)
+	if err != nil {
+		return nil, fmt.Errorf("malformed apv: %w", err)
+	}
+
+	return aesKeyUnwrap(concatKDF(secret, h.Alg, apu, apv, 128), encryptedKey)
+}
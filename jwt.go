@@ -0,0 +1,140 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	cose "github.com/veraison/go-cose"
+)
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// SignJWT serializes the receiver Eat as JSON and signs it with the supplied
+// signer, returning a JWT in JWS compact serialization.  Only the WithKeyID
+// option applies to JWTs; the kid must be UTF-8.  cnf claims are checked as
+// in Sign.
+//
+//nolint:gocritic
+func (e Eat) SignJWT(rand io.Reader, signer cose.Signer, opts ...SignOption) (string, error) {
+	var o signOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.cwtTag || len(o.headerClaims) > 0 || len(o.x5chain) > 0 {
+		return "", errors.New("only the key ID option is supported for JWTs")
+	}
+
+	if err := e.validateCnf(); err != nil {
+		return "", err
+	}
+
+	alg, ok := jwkAlgorithms[signer.Algorithm()]
+	if !ok {
+		return "", fmt.Errorf("no JWS algorithm for %s", signer.Algorithm())
+	}
+
+	h := jwsHeader{Alg: alg, Typ: "JWT"}
+
+	if o.kid != nil {
+		if !utf8.Valid(o.kid) {
+			return "", errors.New("JWT kid must be UTF-8")
+		}
+		h.Kid = string(o.kid)
+	}
+
+	header, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := e.ToJSON()
+	if err != nil {
+		return "", fmt.Errorf("encoding claims-set: %w", err)
+	}
+
+	signingInput := encodeBinaryData(header) + "." + encodeBinaryData(payload)
+
+	sig, err := signer.Sign(rand, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("signing: %w", err)
+	}
+
+	return signingInput + "." + encodeBinaryData(sig), nil
+}
+
+// VerifyJWT verifies the supplied JWT in JWS compact serialization with the key
+// found by the supplied resolver and decodes its claims-set.  The kid header
+// parameter, if any, is passed to the resolver as the key ID.
+func VerifyJWT(token string, resolver KeyResolver) (*VerifiedToken, error) {
+	if resolver == nil {
+		return nil, errors.New("no key resolver")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+
+	data, err := decodeBinaryData(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	var h jwsHeader
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	alg, ok := lookupJWKName(jwkAlgorithms, h.Alg)
+	if !ok {
+		return nil, fmt.Errorf("unsupported JWS algorithm %q", h.Alg)
+	}
+
+	payload, err := decodeBinaryData(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT payload: %w", err)
+	}
+
+	e, err := parseJSONClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := decodeBinaryData(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+
+	q := newKeyQuery(cose.Headers{}, alg, e)
+	if h.Kid != "" {
+		q.KeyID = []byte(h.Kid)
+	}
+
+	key, err := resolver.ResolveKey(q)
+	if err != nil {
+		return nil, fmt.Errorf("resolving key: %w", err)
+	}
+
+	verifier, err := cose.NewVerifier(alg, key)
+	if err != nil {
+		return nil, fmt.Errorf("resolving key: %w", err)
+	}
+
+	if err := verifier.Verify([]byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, fmt.Errorf("verifying signature: %w", err)
+	}
+
+	return &VerifiedToken{Eat: e}, nil
+}
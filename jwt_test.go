@@ -0,0 +1,59 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignJWT_VerifyJWT(t *testing.T) {
+	signer, keyResolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	jwt, err := e.SignJWT(rand.Reader, signer, WithKeyID([]byte("kid-1")))
+	require.Nil(t, err)
+
+	parsed, err := Parse([]byte(jwt))
+	require.Nil(t, err)
+	assert.Equal(t, FormatJWT, parsed.Format)
+
+	var kid []byte
+	resolver := KeyResolverFunc(func(q KeyQuery) (crypto.PublicKey, error) {
+		kid = q.KeyID
+		return keyResolver.ResolveKey(q)
+	})
+
+	actual, err := VerifyJWT(jwt, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+	assert.Equal(t, []byte("kid-1"), kid)
+}
+
+func TestSignJWT_unsupported_option(t *testing.T) {
+	signer, _ := newTestSignerResolver(t)
+
+	_, err := newTestSignedClaims(t).SignJWT(rand.Reader, signer, WithCWTTag())
+	assert.EqualError(t, err, "only the key ID option is supported for JWTs")
+}
+
+func TestVerifyJWT_bad_signature(t *testing.T) {
+	signer, _ := newTestSignerResolver(t)
+	_, resolver := newTestSignerResolver(t)
+
+	jwt, err := newTestSignedClaims(t).SignJWT(rand.Reader, signer)
+	require.Nil(t, err)
+
+	_, err = VerifyJWT(jwt, resolver)
+	assert.ErrorContains(t, err, "verifying signature: ")
+
+	parts := strings.Split(jwt, ".")
+	_, err = VerifyJWT(encodeBinaryData([]byte(`{"alg":"none"}`))+"."+parts[1]+".", resolver)
+	assert.EqualError(t, err, `unsupported JWS algorithm "none"`)
+}
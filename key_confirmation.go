@@ -3,17 +3,144 @@
 
 package eat
 
-import cose "github.com/veraison/go-cose"
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	cose "github.com/veraison/go-cose"
+)
 
 type KeyConfirmation struct {
-	Key *COSEKey `cbor:"1,keyasint,omitempty" json:"jwk,omitempty"`
-	// TODO: EncryptedKey (currently go-cose doesn't support COSE_Encrypt0 / COSE_Encrypt)
-	Kid           *[]byte `cbor:"3,keyasint,omitempty" json:"kid,omitempty"`
-	KeyThumbprint *[]byte `cbor:"5,keyasint,omitempty" json:"jkt,omitempty"`
+	Key           *COSEKey      `cbor:"1,keyasint,omitempty" json:"jwk,omitempty"`
+	EncryptedKey  *EncryptedKey `cbor:"2,keyasint,omitempty" json:"jwe,omitempty"`
+	Kid           *[]byte       `cbor:"3,keyasint,omitempty" json:"kid,omitempty"`
+	KeyThumbprint *[]byte       `cbor:"5,keyasint,omitempty" json:"jkt,omitempty"`
+}
+
+/*
+Encrypted_COSE_Key = COSE_Encrypt0 / COSE_Encrypt
+*/
+
+// EncryptedKey is an encrypted confirmation key.  In CBOR, it is a
+// COSE_Encrypt0 or COSE_Encrypt message carrying a COSE_Key (RFC 8747); in
+// JSON, a JWE in compact serialization carrying a JWK (RFC 7800).  The two
+// forms cannot be converted into each other without decrypting the key.
+type EncryptedKey struct {
+	// COSE is the encoded COSE_Encrypt0 or COSE_Encrypt message
+	COSE []byte
+	// JWE is the JWE compact serialization
+	JWE string
+}
+
+// MarshalCBOR encodes the receiver EncryptedKey as its COSE message
+func (k EncryptedKey) MarshalCBOR() ([]byte, error) {
+	if len(k.COSE) == 0 {
+		return nil, errors.New("no COSE encrypted key")
+	}
+
+	if err := checkEncryptedCOSEKey(k.COSE); err != nil {
+		return nil, err
+	}
+
+	return k.COSE, nil
+}
+
+// UnmarshalCBOR decodes a COSE_Encrypt0 or COSE_Encrypt message, tagged or
+// untagged, into the receiver EncryptedKey
+func (k *EncryptedKey) UnmarshalCBOR(data []byte) error {
+	if err := checkEncryptedCOSEKey(data); err != nil {
+		return err
+	}
+
+	*k = EncryptedKey{COSE: append([]byte(nil), data...)}
+
+	return nil
+}
+
+// MarshalJSON encodes the receiver EncryptedKey as a JWE JSON string
+func (k EncryptedKey) MarshalJSON() ([]byte, error) {
+	if k.JWE == "" {
+		return nil, errors.New("no JWE encrypted key")
+	}
+
+	if err := checkEncryptedJWK(k.JWE); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(k.JWE)
+}
+
+// UnmarshalJSON decodes a JWE JSON string into the receiver EncryptedKey
+func (k *EncryptedKey) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if err := checkEncryptedJWK(s); err != nil {
+		return err
+	}
+
+	*k = EncryptedKey{JWE: s}
+
+	return nil
+}
+
+func checkEncryptedCOSEKey(data []byte) error {
+	content := data
+
+	if isCBORTag(data) {
+		var tag cbor.RawTag
+		if err := dm.Unmarshal(data, &tag); err != nil {
+			return fmt.Errorf("malformed encrypted COSE_Key: %w", err)
+		}
+
+		switch tag.Number {
+		case cborTagCOSEEncrypt0, cborTagCOSEEncrypt:
+		default:
+			return fmt.Errorf("encrypted COSE_Key: unexpected CBOR tag %d", tag.Number)
+		}
+
+		content = tag.Content
+	}
+
+	var msg []cbor.RawMessage
+	if err := dm.Unmarshal(content, &msg); err != nil {
+		return fmt.Errorf("malformed encrypted COSE_Key: %w", err)
+	}
+
+	// COSE_Encrypt0 has 3 items, COSE_Encrypt 4 (the last being recipients)
+	if len(msg) != 3 && len(msg) != 4 {
+		return fmt.Errorf("malformed encrypted COSE_Key: expecting 3 or 4 items, found %d", len(msg))
+	}
+
+	return nil
+}
+
+func checkEncryptedJWK(s string) error {
+	if strings.Count(s, ".") != 4 {
+		return errors.New("encrypted JWK is not a JWE in compact serialization")
+	}
+	return nil
 }
 
+// KeyTypeRSA is the COSE key type for RSA keys (RFC 8230), which is not
+// defined by go-cose
+const KeyTypeRSA cose.KeyType = 3
+
 /*
-NOTE: supports only OKP and EC2 key
+NOTE: supports OKP, EC2, RSA and Symmetric keys
 
 	COSE_Key = {
 	    1 => tstr / int,          ; kty
@@ -23,17 +150,656 @@ NOTE: supports only OKP and EC2 key
 	    ? 5 => bstr,              ; Base IV
 	    * label => values
 	}
+
+The struct tags describe the encoding of OKP and EC2 keys; RSA (RFC 8230) and
+Symmetric keys reuse the negative labels and are encoded by MarshalCBOR.  In
+JSON, a COSEKey is serialized as a JWK (RFC 7517).
 */
 type COSEKey struct {
-	Type      cose.KeyType   `cbor:"1,keyasint" json:"kty"`
-	ID        []byte         `cbor:"2,keyasint,omitempty" json:"kid,omitempty"`
-	Algorithm cose.Algorithm `cbor:"3:keyasint,omitempty" json:"alg,omitempty"`
-	Ops       []cose.KeyOp   `cbor:"4,keyasint,omitempty" json:"ops,omitempty"`
+	Type      cose.KeyType   `cbor:"1,keyasint"`
+	ID        []byte         `cbor:"2,keyasint,omitempty"`
+	Algorithm cose.Algorithm `cbor:"3,keyasint,omitempty"`
+	Ops       []cose.KeyOp   `cbor:"4,keyasint,omitempty"`
+	BaseIV    []byte         `cbor:"5,keyasint,omitempty"`
+
+	// Additional parameter pairs for OKP and EC2.  D is also the RSA private
+	// exponent.
+	Crv cose.Curve `cbor:"-1,keyasint,omitempty"`
+	X   []byte     `cbor:"-2,keyasint,omitempty"`
+	Y   []byte     `cbor:"-3,keyasint,omitempty"`
+	D   []byte     `cbor:"-4,keyasint,omitempty"`
+
+	// Additional parameters for RSA
+	N    []byte `cbor:"-"`
+	E    []byte `cbor:"-"`
+	P    []byte `cbor:"-"`
+	Q    []byte `cbor:"-"`
+	DP   []byte `cbor:"-"`
+	DQ   []byte `cbor:"-"`
+	QInv []byte `cbor:"-"`
+
+	// Additional parameter for Symmetric
+	K []byte `cbor:"-"`
+}
+
+type coseKeyCommon struct {
+	Type      cose.KeyType   `cbor:"1,keyasint"`
+	ID        []byte         `cbor:"2,keyasint,omitempty"`
+	Algorithm cose.Algorithm `cbor:"3,keyasint,omitempty"`
+	Ops       []cose.KeyOp   `cbor:"4,keyasint,omitempty"`
 	BaseIV    []byte         `cbor:"5,keyasint,omitempty"`
+}
+
+type coseKeyRSA struct {
+	coseKeyCommon
+	N    []byte `cbor:"-1,keyasint,omitempty"`
+	E    []byte `cbor:"-2,keyasint,omitempty"`
+	D    []byte `cbor:"-3,keyasint,omitempty"`
+	P    []byte `cbor:"-4,keyasint,omitempty"`
+	Q    []byte `cbor:"-5,keyasint,omitempty"`
+	DP   []byte `cbor:"-6,keyasint,omitempty"`
+	DQ   []byte `cbor:"-7,keyasint,omitempty"`
+	QInv []byte `cbor:"-8,keyasint,omitempty"`
+}
+
+type coseKeySymmetric struct {
+	coseKeyCommon
+	K []byte `cbor:"-1,keyasint,omitempty"`
+}
+
+// coseKeyOKPEC2 has the encoding described by the COSEKey struct tags
+type coseKeyOKPEC2 COSEKey
+
+// MarshalCBOR encodes the receiver COSEKey as a COSE_Key, using the labels of
+// its key type
+//
+//nolint:gocritic
+func (k COSEKey) MarshalCBOR() ([]byte, error) {
+	common := coseKeyCommon{
+		Type:      k.Type,
+		ID:        k.ID,
+		Algorithm: k.Algorithm,
+		Ops:       k.Ops,
+		BaseIV:    k.BaseIV,
+	}
+
+	switch k.Type {
+	case KeyTypeRSA:
+		return em.Marshal(coseKeyRSA{
+			coseKeyCommon: common,
+			N:             k.N,
+			E:             k.E,
+			D:             k.D,
+			P:             k.P,
+			Q:             k.Q,
+			DP:            k.DP,
+			DQ:            k.DQ,
+			QInv:          k.QInv,
+		})
+	case cose.KeyTypeSymmetric:
+		return em.Marshal(coseKeySymmetric{coseKeyCommon: common, K: k.K})
+	default:
+		return em.Marshal(coseKeyOKPEC2(k))
+	}
+}
+
+// UnmarshalCBOR decodes a COSE_Key into the receiver COSEKey, interpreting
+// the labels according to the key type
+func (k *COSEKey) UnmarshalCBOR(data []byte) error {
+	var kty struct {
+		Type cose.KeyType `cbor:"1,keyasint"`
+	}
+
+	if err := dm.Unmarshal(data, &kty); err != nil {
+		return err
+	}
+
+	switch kty.Type {
+	case KeyTypeRSA:
+		var v coseKeyRSA
+		if err := dm.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*k = COSEKey{
+			N: v.N, E: v.E, D: v.D, P: v.P, Q: v.Q, DP: v.DP, DQ: v.DQ, QInv: v.QInv,
+		}
+		k.setCommon(v.coseKeyCommon)
+	case cose.KeyTypeSymmetric:
+		var v coseKeySymmetric
+		if err := dm.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*k = COSEKey{K: v.K}
+		k.setCommon(v.coseKeyCommon)
+	default:
+		var v coseKeyOKPEC2
+		if err := dm.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*k = COSEKey(v)
+	}
+
+	return nil
+}
+
+func (k *COSEKey) setCommon(c coseKeyCommon) {
+	k.Type = c.Type
+	k.ID = c.ID
+	k.Algorithm = c.Algorithm
+	k.Ops = c.Ops
+	k.BaseIV = c.BaseIV
+}
+
+type jwk struct {
+	Kty    string     `json:"kty"`
+	Kid    string     `json:"kid,omitempty"`
+	Alg    string     `json:"alg,omitempty"`
+	KeyOps []string   `json:"key_ops,omitempty"`
+	Crv    string     `json:"crv,omitempty"`
+	X      binaryData `json:"x,omitempty"`
+	Y      binaryData `json:"y,omitempty"`
+	N      binaryData `json:"n,omitempty"`
+	E      binaryData `json:"e,omitempty"`
+	D      binaryData `json:"d,omitempty"`
+	P      binaryData `json:"p,omitempty"`
+	Q      binaryData `json:"q,omitempty"`
+	DP     binaryData `json:"dp,omitempty"`
+	DQ     binaryData `json:"dq,omitempty"`
+	QI     binaryData `json:"qi,omitempty"`
+	K      binaryData `json:"k,omitempty"`
+}
+
+var jwkKeyTypes = map[cose.KeyType]string{
+	cose.KeyTypeOKP:       "OKP",
+	cose.KeyTypeEC2:       "EC",
+	KeyTypeRSA:            "RSA",
+	cose.KeyTypeSymmetric: "oct",
+}
+
+var jwkCurves = map[cose.Curve]string{
+	cose.CurveP256:    "P-256",
+	cose.CurveP384:    "P-384",
+	cose.CurveP521:    "P-521",
+	cose.CurveX25519:  "X25519",
+	cose.CurveX448:    "X448",
+	cose.CurveEd25519: "Ed25519",
+	cose.CurveEd448:   "Ed448",
+}
+
+var jwkAlgorithms = map[cose.Algorithm]string{
+	cose.AlgorithmES256: "ES256",
+	cose.AlgorithmES384: "ES384",
+	cose.AlgorithmES512: "ES512",
+	cose.AlgorithmEdDSA: "EdDSA",
+	cose.AlgorithmPS256: "PS256",
+	cose.AlgorithmPS384: "PS384",
+	cose.AlgorithmPS512: "PS512",
+	cose.AlgorithmRS256: "RS256",
+	cose.AlgorithmRS384: "RS384",
+	cose.AlgorithmRS512: "RS512",
+}
+
+func lookupJWKName[T comparable](names map[T]string, name string) (T, bool) {
+	for k, v := range names {
+		if v == name {
+			return k, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// MarshalJSON encodes the receiver COSEKey as a JWK (RFC 7517).  The kid must
+// be valid UTF-8 and the Base IV, which has no JWK counterpart, must be unset.
+//
+//nolint:gocritic
+func (k COSEKey) MarshalJSON() ([]byte, error) {
+	var (
+		j  jwk
+		ok bool
+	)
+
+	if j.Kty, ok = jwkKeyTypes[k.Type]; !ok {
+		return nil, fmt.Errorf("no JWK key type for %s", k.Type)
+	}
+
+	if !utf8.Valid(k.ID) {
+		return nil, errors.New("kid is not valid UTF-8")
+	}
+	j.Kid = string(k.ID)
+
+	if k.Algorithm != cose.AlgorithmReserved {
+		if j.Alg, ok = jwkAlgorithms[k.Algorithm]; !ok {
+			return nil, fmt.Errorf("no JWK algorithm for %s", k.Algorithm)
+		}
+	}
+
+	for _, op := range k.Ops {
+		if _, ok := cose.KeyOpFromString(op.String()); !ok {
+			return nil, fmt.Errorf("no JWK key operation for %s", op)
+		}
+		j.KeyOps = append(j.KeyOps, op.String())
+	}
+
+	if len(k.BaseIV) != 0 {
+		return nil, errors.New("base IV cannot be represented in a JWK")
+	}
+
+	switch k.Type {
+	case cose.KeyTypeOKP, cose.KeyTypeEC2:
+		if j.Crv, ok = jwkCurves[k.Crv]; !ok {
+			return nil, fmt.Errorf("no JWK curve for %s", k.Crv)
+		}
+		j.X, j.Y, j.D = k.X, k.Y, k.D
+	case KeyTypeRSA:
+		j.N, j.E, j.D = k.N, k.E, k.D
+		j.P, j.Q, j.DP, j.DQ, j.QI = k.P, k.Q, k.DP, k.DQ, k.QInv
+	case cose.KeyTypeSymmetric:
+		j.K = k.K
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a JWK (RFC 7517) into the receiver COSEKey
+func (k *COSEKey) UnmarshalJSON(data []byte) error {
+	var j jwk
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	var (
+		v  COSEKey
+		ok bool
+	)
+
+	if v.Type, ok = lookupJWKName(jwkKeyTypes, j.Kty); !ok {
+		return fmt.Errorf("unsupported JWK key type %q", j.Kty)
+	}
+
+	if j.Kid != "" {
+		v.ID = []byte(j.Kid)
+	}
+
+	if j.Alg != "" {
+		if v.Algorithm, ok = lookupJWKName(jwkAlgorithms, j.Alg); !ok {
+			return fmt.Errorf("unsupported JWK algorithm %q", j.Alg)
+		}
+	}
+
+	for _, s := range j.KeyOps {
+		op, ok := cose.KeyOpFromString(s)
+		if !ok {
+			return fmt.Errorf("unsupported JWK key operation %q", s)
+		}
+		v.Ops = append(v.Ops, op)
+	}
+
+	switch v.Type {
+	case cose.KeyTypeOKP, cose.KeyTypeEC2:
+		if v.Crv, ok = lookupJWKName(jwkCurves, j.Crv); !ok {
+			return fmt.Errorf("unsupported JWK curve %q", j.Crv)
+		}
+		v.X, v.Y, v.D = j.X, j.Y, j.D
+	case KeyTypeRSA:
+		v.N, v.E, v.D = j.N, j.E, j.D
+		v.P, v.Q, v.DP, v.DQ, v.QInv = j.P, j.Q, j.DP, j.DQ, j.QI
+	case cose.KeyTypeSymmetric:
+		v.K = j.K
+	}
+
+	*k = v
+
+	return nil
+}
+
+// NewCOSEKeyFromPublic returns the COSEKey describing the supplied public key.
+// Supported types are *ecdsa.PublicKey, ed25519.PublicKey and *rsa.PublicKey.
+func NewCOSEKeyFromPublic(pub crypto.PublicKey) (*COSEKey, error) {
+	switch v := pub.(type) {
+	case *ecdsa.PublicKey:
+		crv, size, err := coseCurve(v.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &COSEKey{
+			Type: cose.KeyTypeEC2,
+			Crv:  crv,
+			X:    v.X.FillBytes(make([]byte, size)),
+			Y:    v.Y.FillBytes(make([]byte, size)),
+		}, nil
+	case ed25519.PublicKey:
+		return &COSEKey{
+			Type: cose.KeyTypeOKP,
+			Crv:  cose.CurveEd25519,
+			X:    append([]byte(nil), v...),
+		}, nil
+	case *rsa.PublicKey:
+		return &COSEKey{
+			Type: KeyTypeRSA,
+			N:    v.N.Bytes(),
+			E:    big.NewInt(int64(v.E)).Bytes(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// NewCOSEKeyFromPrivate returns the COSEKey describing the supplied private
+// key.  Supported types are *ecdsa.PrivateKey, ed25519.PrivateKey,
+// *rsa.PrivateKey (with two primes) and []byte, for symmetric keys.
+func NewCOSEKeyFromPrivate(priv crypto.PrivateKey) (*COSEKey, error) {
+	switch v := priv.(type) {
+	case *ecdsa.PrivateKey:
+		k, err := NewCOSEKeyFromPublic(&v.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		k.D = v.D.FillBytes(make([]byte, len(k.X)))
+		return k, nil
+	case ed25519.PrivateKey:
+		k, err := NewCOSEKeyFromPublic(v.Public())
+		if err != nil {
+			return nil, err
+		}
+		k.D = v.Seed()
+		return k, nil
+	case *rsa.PrivateKey:
+		if len(v.Primes) != 2 {
+			return nil, errors.New("multi-prime RSA keys are not supported")
+		}
+		k, err := NewCOSEKeyFromPublic(&v.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		p, q := v.Primes[0], v.Primes[1]
+		k.D = v.D.Bytes()
+		k.P = p.Bytes()
+		k.Q = q.Bytes()
+		// the CRT values are computed here rather than with Precompute, which
+		// would modify the caller's key
+		one := big.NewInt(1)
+		k.DP = new(big.Int).Mod(v.D, new(big.Int).Sub(p, one)).Bytes()
+		k.DQ = new(big.Int).Mod(v.D, new(big.Int).Sub(q, one)).Bytes()
+		qInv := new(big.Int).ModInverse(q, p)
+		if qInv == nil {
+			return nil, errors.New("invalid RSA primes")
+		}
+		k.QInv = qInv.Bytes()
+		return k, nil
+	case []byte:
+		return &COSEKey{Type: cose.KeyTypeSymmetric, K: append([]byte(nil), v...)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+func coseCurve(c elliptic.Curve) (cose.Curve, int, error) {
+	switch c {
+	case elliptic.P256():
+		return cose.CurveP256, 32, nil
+	case elliptic.P384():
+		return cose.CurveP384, 48, nil
+	case elliptic.P521():
+		return cose.CurveP521, 66, nil
+	default:
+		return cose.CurveReserved, 0, fmt.Errorf("unsupported curve %s", c.Params().Name)
+	}
+}
+
+// toCOSE returns the receiver OKP or EC2 COSEKey as a go-cose Key.  The
+// algorithm is left for go-cose to derive from the curve.
+//
+//nolint:gocritic
+func (k COSEKey) toCOSE() (*cose.Key, error) {
+	key := cose.Key{
+		Type:   k.Type,
+		ID:     k.ID,
+		Params: map[any]any{},
+	}
+
+	switch k.Type {
+	case cose.KeyTypeOKP:
+		key.Params[cose.KeyLabelOKPCurve] = k.Crv
+		key.Params[cose.KeyLabelOKPX] = k.X
+		if k.D != nil {
+			key.Params[cose.KeyLabelOKPD] = k.D
+		}
+	case cose.KeyTypeEC2:
+		key.Params[cose.KeyLabelEC2Curve] = k.Crv
+		key.Params[cose.KeyLabelEC2X] = k.X
+		key.Params[cose.KeyLabelEC2Y] = k.Y
+		if k.D != nil {
+			key.Params[cose.KeyLabelEC2D] = k.D
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Type)
+	}
+
+	return &key, nil
+}
+
+// PublicKey returns the public key described by the receiver COSEKey: an
+// *ecdsa.PublicKey, an ed25519.PublicKey or an *rsa.PublicKey
+//
+//nolint:gocritic
+func (k COSEKey) PublicKey() (crypto.PublicKey, error) {
+	if k.Type == KeyTypeRSA {
+		return k.rsaPublicKey()
+	}
+
+	key, err := k.toCOSE()
+	if err != nil {
+		return nil, err
+	}
+
+	return key.PublicKey()
+}
+
+// PrivateKey returns the private key described by the receiver COSEKey: an
+// *ecdsa.PrivateKey, an ed25519.PrivateKey, an *rsa.PrivateKey or, for
+// symmetric keys, the key bytes
+//
+//nolint:gocritic
+func (k COSEKey) PrivateKey() (crypto.PrivateKey, error) {
+	switch k.Type {
+	case KeyTypeRSA:
+		return k.rsaPrivateKey()
+	case cose.KeyTypeSymmetric:
+		if len(k.K) == 0 {
+			return nil, errors.New("missing symmetric key value")
+		}
+		return append([]byte(nil), k.K...), nil
+	}
+
+	if len(k.D) == 0 {
+		return nil, errors.New("missing private key")
+	}
+
+	key, err := k.toCOSE()
+	if err != nil {
+		return nil, err
+	}
+
+	return key.PrivateKey()
+}
+
+//nolint:gocritic
+func (k COSEKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	if len(k.N) == 0 || len(k.E) == 0 {
+		return nil, errors.New("missing RSA modulus or exponent")
+	}
+
+	e := new(big.Int).SetBytes(k.E)
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("RSA public exponent too large")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(k.N), E: int(e.Int64())}, nil
+}
+
+//nolint:gocritic
+func (k COSEKey) rsaPrivateKey() (*rsa.PrivateKey, error) {
+	pub, err := k.rsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(k.D) == 0 || len(k.P) == 0 || len(k.Q) == 0 {
+		return nil, errors.New("missing RSA private exponent or primes")
+	}
+
+	priv := &rsa.PrivateKey{
+		PublicKey: *pub,
+		D:         new(big.Int).SetBytes(k.D),
+		Primes:    []*big.Int{new(big.Int).SetBytes(k.P), new(big.Int).SetBytes(k.Q)},
+	}
+
+	if err := priv.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid RSA private key: %w", err)
+	}
+
+	priv.Precompute()
+
+	return priv, nil
+}
+
+// okpKeySizes and ec2KeySizes are the lengths in bytes of the coordinates and
+// private keys on each curve
+var (
+	okpKeySizes = map[cose.Curve]int{
+		cose.CurveX25519:  32,
+		cose.CurveX448:    56,
+		cose.CurveEd25519: 32,
+		cose.CurveEd448:   57,
+	}
+	ec2KeySizes = map[cose.Curve]int{
+		cose.CurveP256: 32,
+		cose.CurveP384: 48,
+		cose.CurveP521: 66,
+	}
+)
+
+// Validate checks that the receiver COSEKey has the parameters required by its
+// key type, and that the lengths of coordinates and private keys match the
+// curve.  EC2 points must be on the curve.
+//
+//nolint:gocritic
+func (k COSEKey) Validate() error {
+	switch k.Type {
+	case cose.KeyTypeOKP:
+		size, ok := okpKeySizes[k.Crv]
+		if !ok {
+			return fmt.Errorf("invalid curve %s for OKP key", k.Crv)
+		}
+		if len(k.X) != size {
+			return fmt.Errorf("x: expecting %d bytes for %s, found %d", size, k.Crv, len(k.X))
+		}
+		if k.Y != nil {
+			return errors.New("unexpected y parameter in OKP key")
+		}
+		return checkPrivateKeySize(k.D, size, k.Crv)
+	case cose.KeyTypeEC2:
+		size, ok := ec2KeySizes[k.Crv]
+		if !ok {
+			return fmt.Errorf("invalid curve %s for EC2 key", k.Crv)
+		}
+		if len(k.X) != size {
+			return fmt.Errorf("x: expecting %d bytes for %s, found %d", size, k.Crv, len(k.X))
+		}
+		if len(k.Y) != size {
+			return fmt.Errorf("y: expecting %d bytes for %s, found %d", size, k.Crv, len(k.Y))
+		}
+		if err := checkEC2Point(k.Crv, k.X, k.Y); err != nil {
+			return err
+		}
+		return checkPrivateKeySize(k.D, size, k.Crv)
+	case KeyTypeRSA:
+		if len(k.N) == 0 || len(k.E) == 0 {
+			return errors.New("missing RSA modulus or exponent")
+		}
+		hasPrivate := k.D != nil || k.P != nil || k.Q != nil ||
+			k.DP != nil || k.DQ != nil || k.QInv != nil
+		if hasPrivate {
+			if _, err := k.rsaPrivateKey(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case cose.KeyTypeSymmetric:
+		if len(k.K) == 0 {
+			return errors.New("missing symmetric key value")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %s", k.Type)
+	}
+}
+
+func checkPrivateKeySize(d []byte, size int, crv cose.Curve) error {
+	if d != nil && len(d) != size {
+		return fmt.Errorf("d: expecting %d bytes for %s, found %d", size, crv, len(d))
+	}
+	return nil
+}
+
+func checkEC2Point(crv cose.Curve, x, y []byte) error {
+	var c ecdh.Curve
+
+	switch crv {
+	case cose.CurveP256:
+		c = ecdh.P256()
+	case cose.CurveP384:
+		c = ecdh.P384()
+	case cose.CurveP521:
+		c = ecdh.P521()
+	}
+
+	point := append(append([]byte{0x04}, x...), y...)
+
+	if _, err := c.NewPublicKey(point); err != nil {
+		return fmt.Errorf("point is not on curve %s", crv)
+	}
+
+	return nil
+}
+
+// IsPrivate returns true if the receiver COSEKey carries secret material, i.e.,
+// a private key or a symmetric key
+//
+//nolint:gocritic
+func (k COSEKey) IsPrivate() bool {
+	return k.D != nil || k.P != nil || k.Q != nil || k.DP != nil ||
+		k.DQ != nil || k.QInv != nil || k.K != nil
+}
+
+// Validate checks that the receiver KeyConfirmation has a confirmation method
+// and, if it carries a key in the clear, that the key is valid and has no
+// secret material: private keys must never be disclosed in a token and
+// symmetric keys must be encrypted (RFC 8747).
+//
+//nolint:gocritic
+func (c KeyConfirmation) Validate() error {
+	if c.Key == nil && c.EncryptedKey == nil && c.Kid == nil && c.KeyThumbprint == nil {
+		return errors.New("empty cnf claim")
+	}
+
+	if c.Key == nil {
+		return nil
+	}
+
+	if err := c.Key.Validate(); err != nil {
+		return fmt.Errorf("COSE_Key: %w", err)
+	}
+
+	if c.Key.Type == cose.KeyTypeSymmetric {
+		return errors.New("COSE_Key: symmetric key must be encrypted")
+	}
+
+	if c.Key.IsPrivate() {
+		return errors.New("COSE_Key: private key parameters must not be disclosed")
+	}
 
-	// Additional parameter pairs for OKP and EC2.
-	Crv cose.Curve `cbor:"-1,keyasint,omitempty" json:"crv,omitempty"`
-	X   []byte     `cbor:"-2,keyasint,omitempty" json:"x,omitempty"`
-	Y   []byte     `cbor:"-3,keyasint,omitempty" json:"y,omitempty"`
-	D   []byte     `cbor:"-4,keyasint,omitempty" json:"d,omitempty"`
+	return nil
 }
@@ -4,9 +4,18 @@
 package eat
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
 	"testing"
 
+	cbor "github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/veraison/go-cose"
 )
 
@@ -139,9 +148,11 @@ func TestKeyConfirmation_CBORUnmarshal_OK(t *testing.T) {
 	assert.Equal(t, keyThumbprint, *cnf.KeyThumbprint)
 
 	cnf = KeyConfirmation{}
-	// the encoded data includes the EncryptedKey, but its ignored
 	assert.Nil(t, dm.Unmarshal(encodedKeyConfirmationWithEncryptedKey, &cnf))
 
+	assert.NotNil(t, cnf.EncryptedKey)
+	assert.Equal(t, encodedKeyConfirmationWithEncryptedKey[82:161], cnf.EncryptedKey.COSE)
+
 	assert.NotNil(t, cnf.Key)
 	assert.Equal(t, kty, cnf.Key.Type)
 	assert.Equal(t, kid, cnf.Key.ID)
@@ -150,3 +161,266 @@ func TestKeyConfirmation_CBORUnmarshal_OK(t *testing.T) {
 	assert.Equal(t, y, cnf.Key.Y)
 	assert.Nil(t, cnf.Key.D)
 }
+
+func TestKeyConfirmation_EncryptedKey_RoundTrip(t *testing.T) {
+	var cnf KeyConfirmation
+	require.Nil(t, dm.Unmarshal(encodedKeyConfirmationWithEncryptedKey, &cnf))
+
+	encoded, err := em.Marshal(cnf)
+	require.Nil(t, err)
+	assert.Equal(t, encodedKeyConfirmationWithEncryptedKey, encoded)
+
+	_, err = json.Marshal(cnf)
+	assert.ErrorContains(t, err, "no JWE encrypted key")
+
+	jwe := `{"jwe":"eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkExMjhHQ00ifQ.a.b.c.d"}`
+
+	cnf = KeyConfirmation{}
+	require.Nil(t, json.Unmarshal([]byte(jwe), &cnf))
+	assert.Equal(t, "eyJhbGciOiJSU0EtT0FFUCIsImVuYyI6IkExMjhHQ00ifQ.a.b.c.d", cnf.EncryptedKey.JWE)
+
+	encoded, err = json.Marshal(cnf)
+	require.Nil(t, err)
+	assert.JSONEq(t, jwe, string(encoded))
+}
+
+func TestKeyConfirmation_EncryptedKey_NG(t *testing.T) {
+	var k EncryptedKey
+
+	// COSE_Sign1 tag
+	assert.EqualError(t, dm.Unmarshal([]byte{0xd2, 0x83, 0x40, 0xa0, 0x40}, &k),
+		"encrypted COSE_Key: unexpected CBOR tag 18")
+	// array(2)
+	assert.EqualError(t, dm.Unmarshal([]byte{0x82, 0x40, 0xa0}, &k),
+		"malformed encrypted COSE_Key: expecting 3 or 4 items, found 2")
+	assert.EqualError(t, json.Unmarshal([]byte(`"a.b.c"`), &k),
+		"encrypted JWK is not a JWE in compact serialization")
+}
+
+func TestCOSEKey_JWK_RoundTrip(t *testing.T) {
+	key := COSEKey{
+		Type:      kty,
+		ID:        kid,
+		Algorithm: cose.AlgorithmES256,
+		Ops:       []cose.KeyOp{cose.KeyOpVerify},
+		Crv:       crv,
+		X:         x,
+		Y:         y,
+	}
+
+	encoded, err := json.Marshal(key)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{
+		"kty": "EC",
+		"kid": "11",
+		"alg": "ES256",
+		"key_ops": ["verify"],
+		"crv": "P-256",
+		"x": "usWxHK2PmfnHKwXPS54m0kTcGJ90UiglWiGahtagnv8",
+		"y": "IBOL-C3BttVivg-lSreASjpkttcsz-1rb7btKLv8EX4"
+	}`, string(encoded))
+
+	var actual COSEKey
+	require.Nil(t, json.Unmarshal(encoded, &actual))
+	assert.Equal(t, key, actual)
+
+	key.BaseIV = []byte{0x01}
+	_, err = json.Marshal(key)
+	assert.ErrorContains(t, err, "base IV cannot be represented in a JWK")
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"kty":"EC","crv":"P-257"}`), &actual),
+		`unsupported JWK curve "P-257"`)
+}
+
+func TestNewCOSEKeyFromPrivate_RSA_unmodified(t *testing.T) {
+	generated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	priv := &rsa.PrivateKey{PublicKey: generated.PublicKey, D: generated.D, Primes: generated.Primes}
+
+	key, err := NewCOSEKeyFromPrivate(priv)
+	require.Nil(t, err)
+
+	// the caller's key is left as is, and the CRT values are those that
+	// Precompute would have set
+	assert.Nil(t, priv.Precomputed.Dp)
+	assert.Equal(t, generated.Precomputed.Dp.Bytes(), key.DP)
+	assert.Equal(t, generated.Precomputed.Dq.Bytes(), key.DQ)
+	assert.Equal(t, generated.Precomputed.Qinv.Bytes(), key.QInv)
+}
+
+func TestCOSEKey_RSA_Symmetric_CBOR(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	key, err := NewCOSEKeyFromPrivate(priv)
+	require.Nil(t, err)
+	assert.Equal(t, KeyTypeRSA, key.Type)
+
+	encoded, err := em.Marshal(key)
+	require.Nil(t, err)
+
+	var labels map[int64]cbor.RawMessage
+	require.Nil(t, dm.Unmarshal(encoded, &labels))
+	for _, l := range []int64{1, -1, -2, -3, -4, -5, -6, -7, -8} {
+		assert.Contains(t, labels, l)
+	}
+
+	var actual COSEKey
+	require.Nil(t, dm.Unmarshal(encoded, &actual))
+	assert.Equal(t, *key, actual)
+
+	actualPriv, err := actual.PrivateKey()
+	require.Nil(t, err)
+	assert.True(t, priv.Equal(actualPriv))
+
+	sym, err := NewCOSEKeyFromPrivate([]byte("0123456789abcdef"))
+	require.Nil(t, err)
+
+	encoded, err = em.Marshal(sym)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xa2, 0x01, 0x04, 0x20, 0x50}, encoded[:5])
+
+	actual = COSEKey{}
+	require.Nil(t, dm.Unmarshal(encoded, &actual))
+	assert.Equal(t, []byte("0123456789abcdef"), actual.K)
+}
+
+func TestCOSEKey_Conversions(t *testing.T) {
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.Nil(t, err)
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	for _, priv := range []crypto.Signer{ecPriv, edPriv, rsaPriv} {
+		key, err := NewCOSEKeyFromPrivate(priv)
+		require.Nil(t, err)
+
+		actualPriv, err := key.PrivateKey()
+		require.Nil(t, err)
+		assert.True(t, actualPriv.(interface{ Equal(crypto.PrivateKey) bool }).Equal(priv))
+
+		key, err = NewCOSEKeyFromPublic(priv.Public())
+		require.Nil(t, err)
+		assert.Nil(t, key.D)
+
+		actualPub, err := key.PublicKey()
+		require.Nil(t, err)
+		assert.True(t, actualPub.(interface{ Equal(crypto.PublicKey) bool }).Equal(priv.Public()))
+
+		_, err = key.PrivateKey()
+		assert.Error(t, err)
+	}
+}
+
+func TestCOSEKey_Algorithm_CBOR(t *testing.T) {
+	key := COSEKey{Type: kty, Algorithm: cose.AlgorithmES256, Crv: crv, X: x, Y: y}
+
+	encoded, err := em.Marshal(key)
+	require.Nil(t, err)
+
+	var labels map[int64]cbor.RawMessage
+	require.Nil(t, dm.Unmarshal(encoded, &labels))
+	assert.Equal(t, cbor.RawMessage{0x26}, labels[3]) // -7
+
+	var actual COSEKey
+	require.Nil(t, dm.Unmarshal(encoded, &actual))
+	assert.Equal(t, cose.AlgorithmES256, actual.Algorithm)
+}
+
+func TestCOSEKey_Validate(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	edKey, err := NewCOSEKeyFromPrivate(edPriv)
+	require.Nil(t, err)
+
+	for _, key := range []COSEKey{
+		{Type: kty, Crv: crv, X: x, Y: y},
+		*edKey,
+		{Type: cose.KeyTypeSymmetric, K: []byte("secret")},
+	} {
+		assert.Nil(t, key.Validate())
+	}
+
+	offCurve := append([]byte{}, y...)
+	offCurve[31] ^= 0x01
+
+	tvs := []struct {
+		key      COSEKey
+		expected string
+	}{
+		{COSEKey{Type: kty, Crv: cose.CurveEd25519, X: x, Y: y}, "invalid curve Ed25519 for EC2 key"},
+		{COSEKey{Type: kty, Crv: cose.CurveP384, X: x, Y: y}, "x: expecting 48 bytes for P-384, found 32"},
+		{COSEKey{Type: kty, Crv: crv, X: x}, "y: expecting 32 bytes for P-256, found 0"},
+		{COSEKey{Type: kty, Crv: crv, X: x, Y: offCurve}, "point is not on curve P-256"},
+		{COSEKey{Type: kty, Crv: crv, X: x, Y: y, D: []byte{0x01}}, "d: expecting 32 bytes for P-256, found 1"},
+		{COSEKey{Type: cose.KeyTypeOKP, Crv: cose.CurveEd448, X: x}, "x: expecting 57 bytes for Ed448, found 32"},
+		{COSEKey{Type: cose.KeyTypeOKP, Crv: cose.CurveEd25519, X: x, Y: y}, "unexpected y parameter in OKP key"},
+		{COSEKey{Type: KeyTypeRSA, N: x}, "missing RSA modulus or exponent"},
+		{COSEKey{Type: cose.KeyTypeSymmetric}, "missing symmetric key value"},
+		{COSEKey{Type: cose.KeyTypeReserved}, "unsupported key type Reserved"},
+	}
+
+	for _, tv := range tvs {
+		assert.EqualError(t, tv.key.Validate(), tv.expected)
+	}
+}
+
+func TestKeyConfirmation_Validate(t *testing.T) {
+	assert.Nil(t, KeyConfirmation{Key: &COSEKey{Type: kty, Crv: crv, X: x, Y: y}}.Validate())
+	assert.Nil(t, KeyConfirmation{KeyThumbprint: &keyThumbprint}.Validate())
+
+	assert.EqualError(t, KeyConfirmation{}.Validate(), "empty cnf claim")
+
+	priv := newTestP256Key(t)
+	key, err := NewCOSEKeyFromPrivate(priv)
+	require.Nil(t, err)
+
+	cnf := KeyConfirmation{Key: key}
+	assert.EqualError(t, cnf.Validate(), "COSE_Key: private key parameters must not be disclosed")
+
+	e := Eat{CWTClaims: CWTClaims{Cnf: &cnf}}
+	assert.EqualError(t, e.Validate(), "cnf: COSE_Key: private key parameters must not be disclosed")
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, priv)
+	require.Nil(t, err)
+	_, err = e.Sign(rand.Reader, signer)
+	assert.EqualError(t, err, "cnf: COSE_Key: private key parameters must not be disclosed")
+
+	cnf = KeyConfirmation{Key: &COSEKey{Type: cose.KeyTypeSymmetric, K: []byte("secret")}}
+	assert.EqualError(t, cnf.Validate(), "COSE_Key: symmetric key must be encrypted")
+}
+
+func TestEat_nested_submod_cnf(t *testing.T) {
+	priv := newTestP256Key(t)
+	signer, err := cose.NewSigner(cose.AlgorithmES256, priv)
+	require.Nil(t, err)
+
+	secret := KeyConfirmation{Key: &COSEKey{Type: cose.KeyTypeSymmetric, K: []byte("secret")}}
+
+	var inner Submods
+	require.Nil(t, inner.Add("tee", Eat{CWTClaims: CWTClaims{Cnf: &secret}}))
+
+	var outer Submods
+	require.Nil(t, outer.Add("platform", Eat{Submods: &inner}))
+
+	e := Eat{Submods: &outer}
+	expected := "submod platform: submod tee: cnf: COSE_Key: symmetric key must be encrypted"
+
+	_, err = e.ToCBOR()
+	assert.EqualError(t, err, expected)
+
+	_, err = e.ToJSON()
+	assert.EqualError(t, err, expected)
+
+	_, err = e.Sign(rand.Reader, signer)
+	assert.EqualError(t, err, expected)
+
+	_, err = e.SignMulti(rand.Reader, []CoSigner{{Signer: signer}})
+	assert.EqualError(t, err, expected)
+
+	_, err = e.SignJWT(rand.Reader, signer)
+	assert.EqualError(t, err, expected)
+}
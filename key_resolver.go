@@ -0,0 +1,289 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// KeyQuery describes the token whose verification key is being resolved.  The
+// claims are taken from the payload before the signature is verified, so they
+// must only be used to look keys up.
+type KeyQuery struct {
+	// KeyID is the kid of the signer, if known
+	KeyID []byte
+	// Algorithm is the COSE signature algorithm
+	Algorithm cose.Algorithm
+	// Issuer is the iss claim, if present
+	Issuer *string
+	// UEID is the ueid claim, if present
+	UEID *UEID
+	// OemID is the oemid claim, if present
+	OemID *[]byte
+	// Headers are the COSE headers of the token, for resolvers that use the
+	// certificates carried in it
+	Headers cose.Headers
+}
+
+// KeyResolver finds the public key to verify a token with
+type KeyResolver interface {
+	ResolveKey(q KeyQuery) (crypto.PublicKey, error)
+}
+
+// KeyResolverFunc adapts a function to the KeyResolver interface
+type KeyResolverFunc func(q KeyQuery) (crypto.PublicKey, error)
+
+// ResolveKey calls f(q)
+func (f KeyResolverFunc) ResolveKey(q KeyQuery) (crypto.PublicKey, error) {
+	return f(q)
+}
+
+// StaticKeyResolver returns a KeyResolver that always resolves to the supplied
+// public key
+func StaticKeyResolver(key crypto.PublicKey) KeyResolver {
+	return KeyResolverFunc(func(KeyQuery) (crypto.PublicKey, error) {
+		return key, nil
+	})
+}
+
+func newKeyQuery(headers cose.Headers, alg cose.Algorithm, e *Eat) KeyQuery {
+	q := KeyQuery{
+		KeyID:     headerKeyID(headers),
+		Algorithm: alg,
+		Headers:   headers,
+	}
+
+	if e != nil {
+		q.Issuer = e.Issuer
+		q.UEID = e.UEID
+		q.OemID = e.OemID
+	}
+
+	return q
+}
+
+func headerKeyID(headers cose.Headers) []byte {
+	for _, h := range []map[any]any{headers.Protected, headers.Unprotected} {
+		if kid, ok := h[cose.HeaderLabelKeyID].([]byte); ok {
+			return kid
+		}
+	}
+	return nil
+}
+
+/*
+COSE_KeySet = [+COSE_Key]
+*/
+
+// KeySet is a set of trusted keys, such as a COSE_KeySet or a JWK Set, that
+// resolves keys by kid.  Keys can also be registered under the identity of
+// their attester, using its UEID, OemID or issuer as kid.
+type KeySet []COSEKey
+
+// LoadCOSEKeySet reads a CBOR encoded COSE_KeySet from the supplied file
+func LoadCOSEKeySet(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s KeySet
+	if err := dm.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decoding COSE_KeySet: %w", err)
+	}
+
+	return s, s.validate()
+}
+
+// LoadJWKSet reads a JWK Set (RFC 7517) from the supplied file
+func LoadJWKSet(path string) (KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys KeySet `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("decoding JWK Set: %w", err)
+	}
+
+	return jwks.Keys, jwks.Keys.validate()
+}
+
+func (s KeySet) validate() error {
+	if len(s) == 0 {
+		return errors.New("empty key set")
+	}
+
+	for i, k := range s {
+		if err := k.Validate(); err != nil {
+			return fmt.Errorf("key at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveKey returns the key in the receiver KeySet with the kid of the
+// supplied query.  A query without kid resolves to the key whose kid is the
+// UEID, the OemID or the issuer (as UTF-8) of the token, in this order of
+// preference, or else to the only key of the set.  If the key specifies an
+// algorithm, it must be that of the query.
+func (s KeySet) ResolveKey(q KeyQuery) (crypto.PublicKey, error) {
+	var found *COSEKey
+
+	if q.KeyID != nil {
+		if found = s.lookup(q.KeyID); found == nil {
+			return nil, fmt.Errorf("no key with kid %x", q.KeyID)
+		}
+	} else {
+		for _, id := range q.identities() {
+			if found = s.lookup(id); found != nil {
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		if len(s) != 1 {
+			return nil, errors.New("kid, ueid, oemid or iss needed to select a key from the key set")
+		}
+		found = &s[0]
+	}
+
+	if found.Algorithm != cose.AlgorithmReserved && found.Algorithm != q.Algorithm {
+		return nil, fmt.Errorf("key is for algorithm %s, not %s", found.Algorithm, q.Algorithm)
+	}
+
+	return found.PublicKey()
+}
+
+func (s KeySet) lookup(kid []byte) *COSEKey {
+	for i := range s {
+		if bytes.Equal(s[i].ID, kid) {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// identities returns the identity claims of the receiver KeyQuery that are
+// present, in order of preference
+//
+//nolint:gocritic
+func (q KeyQuery) identities() [][]byte {
+	var ids [][]byte
+
+	if q.UEID != nil {
+		ids = append(ids, *q.UEID)
+	}
+	if q.OemID != nil {
+		ids = append(ids, *q.OemID)
+	}
+	if q.Issuer != nil {
+		ids = append(ids, []byte(*q.Issuer))
+	}
+
+	return ids
+}
+
+// X5ChainResolver resolves the key of the leaf certificate of the x5chain
+// header parameter (RFC 9360), after validating the chain against Roots
+type X5ChainResolver struct {
+	// Roots are the trusted root certificates
+	Roots *x509.CertPool
+	// CurrentTime is the time at which the chain is validated.  If zero, the
+	// current time is used.
+	CurrentTime time.Time
+}
+
+// ResolveKey returns the public key of the leaf certificate of the x5chain in
+// the headers of the supplied query
+func (r X5ChainResolver) ResolveKey(q KeyQuery) (crypto.PublicKey, error) {
+	if r.Roots == nil {
+		return nil, errors.New("no root certificates")
+	}
+
+	chain, err := headerX5Chain(q.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		Roots:         r.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   r.CurrentTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("validating x5chain: %w", err)
+	}
+
+	return chain[0].PublicKey, nil
+}
+
+func headerX5Chain(headers cose.Headers) ([]*x509.Certificate, error) {
+	var v any
+
+	for _, h := range []map[any]any{headers.Protected, headers.Unprotected} {
+		if x5chain, ok := h[cose.HeaderLabelX5Chain]; ok {
+			v = x5chain
+			break
+		}
+	}
+
+	var ders [][]byte
+
+	switch t := v.(type) {
+	case nil:
+		return nil, errors.New("no x5chain header parameter")
+	case []byte:
+		ders = [][]byte{t}
+	case [][]byte:
+		ders = t
+	case []any:
+		for _, c := range t {
+			der, ok := c.([]byte)
+			if !ok {
+				return nil, errors.New("malformed x5chain: expecting byte strings")
+			}
+			ders = append(ders, der)
+		}
+	default:
+		return nil, fmt.Errorf("malformed x5chain: unexpected type %T", v)
+	}
+
+	if len(ders) == 0 {
+		return nil, errors.New("empty x5chain")
+	}
+
+	chain := make([]*x509.Certificate, 0, len(ders))
+
+	for i, der := range ders {
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5chain certificate at index %d: %w", i, err)
+		}
+		chain = append(chain, c)
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func newTestKeySet(t *testing.T) (KeySet, []*ecdsa.PrivateKey) {
+	var (
+		s    KeySet
+		keys []*ecdsa.PrivateKey
+	)
+
+	for _, kid := range []string{"key-1", "key-2"} {
+		priv := newTestP256Key(t)
+
+		k, err := NewCOSEKeyFromPublic(priv.Public())
+		require.Nil(t, err)
+		k.ID = []byte(kid)
+		k.Algorithm = cose.AlgorithmES256
+
+		s = append(s, *k)
+		keys = append(keys, priv)
+	}
+
+	return s, keys
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestKeySet_Load(t *testing.T) {
+	s, _ := newTestKeySet(t)
+
+	data, err := em.Marshal(s)
+	require.Nil(t, err)
+
+	actual, err := LoadCOSEKeySet(writeTestFile(t, "keys.cbor", data))
+	require.Nil(t, err)
+	assert.Equal(t, s, actual)
+
+	data, err = json.Marshal(map[string]KeySet{"keys": s})
+	require.Nil(t, err)
+
+	actual, err = LoadJWKSet(writeTestFile(t, "keys.json", data))
+	require.Nil(t, err)
+	assert.Equal(t, s, actual)
+
+	_, err = LoadJWKSet(writeTestFile(t, "empty.json", []byte(`{"keys":[]}`)))
+	assert.EqualError(t, err, "empty key set")
+}
+
+func TestKeySet_ResolveKey(t *testing.T) {
+	s, keys := newTestKeySet(t)
+
+	key, err := s.ResolveKey(KeyQuery{KeyID: []byte("key-2"), Algorithm: cose.AlgorithmES256})
+	require.Nil(t, err)
+	assert.True(t, keys[1].PublicKey.Equal(key))
+
+	_, err = s.ResolveKey(KeyQuery{KeyID: []byte("key-3"), Algorithm: cose.AlgorithmES256})
+	assert.EqualError(t, err, "no key with kid 6b65792d33")
+
+	_, err = s.ResolveKey(KeyQuery{Algorithm: cose.AlgorithmES256})
+	assert.EqualError(t, err, "kid, ueid, oemid or iss needed to select a key from the key set")
+
+	_, err = s.ResolveKey(KeyQuery{KeyID: []byte("key-1"), Algorithm: cose.AlgorithmES384})
+	assert.EqualError(t, err, "key is for algorithm ES256, not ES384")
+
+	key, err = s[:1].ResolveKey(KeyQuery{Algorithm: cose.AlgorithmES256})
+	require.Nil(t, err)
+	assert.True(t, keys[0].PublicKey.Equal(key))
+}
+
+func TestKeySet_ResolveKey_identity(t *testing.T) {
+	s, keys := newTestKeySet(t)
+
+	// key-1 is registered under the UEID of its attester, key-2 under its
+	// issuer
+	s[0].ID = ueID
+	s[1].ID = []byte("https://device.example")
+
+	iss := "https://device.example"
+	oemID := []byte{0x01}
+
+	key, err := s.ResolveKey(KeyQuery{Algorithm: cose.AlgorithmES256, UEID: &ueID, OemID: &oemID, Issuer: &iss})
+	require.Nil(t, err)
+	assert.True(t, keys[0].PublicKey.Equal(key))
+
+	key, err = s.ResolveKey(KeyQuery{Algorithm: cose.AlgorithmES256, OemID: &oemID, Issuer: &iss})
+	require.Nil(t, err)
+	assert.True(t, keys[1].PublicKey.Equal(key))
+
+	// an explicit kid is not overridden by the identity claims
+	_, err = s.ResolveKey(KeyQuery{KeyID: []byte("key-1"), Algorithm: cose.AlgorithmES256, UEID: &ueID})
+	assert.EqualError(t, err, "no key with kid 6b65792d31")
+
+	_, err = s.ResolveKey(KeyQuery{Algorithm: cose.AlgorithmES256, OemID: &oemID})
+	assert.EqualError(t, err, "kid, ueid, oemid or iss needed to select a key from the key set")
+}
+
+func TestVerify_KeySet(t *testing.T) {
+	s, keys := newTestKeySet(t)
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, keys[1])
+	require.Nil(t, err)
+
+	data, err := newTestClaims(t).Sign(rand.Reader, signer, WithKeyID([]byte("key-2")))
+	require.Nil(t, err)
+
+	_, err = Verify(data, s)
+	assert.Nil(t, err)
+
+	data, err = newTestClaims(t).Sign(rand.Reader, signer, WithKeyID([]byte("key-1")))
+	require.Nil(t, err)
+
+	_, err = Verify(data, s)
+	assert.ErrorContains(t, err, "verifying signature")
+}
+
+func TestVerify_KeyResolverFunc(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+
+	e := newTestSignedClaims(t)
+
+	data, err := e.Sign(rand.Reader, signer)
+	require.Nil(t, err)
+
+	byIssuer := KeyResolverFunc(func(q KeyQuery) (crypto.PublicKey, error) {
+		require.NotNil(t, q.Issuer)
+		assert.Equal(t, *e.Issuer, *q.Issuer)
+		assert.Equal(t, e.UEID, q.UEID)
+		return resolver.ResolveKey(q)
+	})
+
+	_, err = Verify(data, byIssuer)
+	assert.Nil(t, err)
+}
+
+func newTestCertificate(
+	t *testing.T, name string, pub crypto.PublicKey, parent *x509.Certificate, signer crypto.Signer,
+) *x509.Certificate {
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	if parent == nil {
+		parent = &template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, parent, pub, signer)
+	require.Nil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	return cert
+}
+
+func TestVerify_X5ChainResolver(t *testing.T) {
+	caKey := newTestP256Key(t)
+	ca := newTestCertificate(t, "root", caKey.Public(), nil, caKey)
+
+	leafKey := newTestP256Key(t)
+	leaf := newTestCertificate(t, "attester", leafKey.Public(), ca, caKey)
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, leafKey)
+	require.Nil(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	for _, chain := range [][][]byte{{leaf.Raw}, {leaf.Raw, ca.Raw}} {
+		data, err := newTestClaims(t).Sign(rand.Reader, signer, WithX5Chain(chain...))
+		require.Nil(t, err)
+
+		_, err = Verify(data, X5ChainResolver{Roots: roots})
+		assert.Nil(t, err)
+	}
+
+	data, err := newTestClaims(t).Sign(rand.Reader, signer, WithX5Chain(leaf.Raw))
+	require.Nil(t, err)
+
+	_, err = Verify(data, X5ChainResolver{Roots: x509.NewCertPool()})
+	assert.ErrorContains(t, err, "validating x5chain")
+
+	data, err = newTestClaims(t).Sign(rand.Reader, signer)
+	require.Nil(t, err)
+
+	_, err = Verify(data, X5ChainResolver{Roots: roots})
+	assert.ErrorContains(t, err, "no x5chain header parameter")
+}
+
+func TestEat_VerifyPoP_KeyResolver(t *testing.T) {
+	s, keys := newTestKeySet(t)
+
+	pop := newTestPoP(t, keys[0])
+
+	kid := []byte("key-1")
+	e := Eat{CWTClaims: CWTClaims{Cnf: &KeyConfirmation{Kid: &kid}}}
+	assert.Nil(t, e.VerifyPoP(pop, s))
+
+	kid = []byte("key-2")
+	assert.ErrorContains(t, e.VerifyPoP(pop, s), "proof of possession")
+}
+
+func TestCSR_VerifyCSREAT_KeyResolver(t *testing.T) {
+	priv := newTestP256Key(t)
+	e := newCSREat(t, &priv.PublicKey)
+
+	signer, resolver := newTestSignerResolver(t)
+
+	token, err := e.Sign(rand.Reader, signer, WithCWTTag())
+	require.Nil(t, err)
+
+	der, err := CreateCertificateRequestWithEAT(
+		rand.Reader, &x509.CertificateRequest{}, priv, MediaTypeCWT, token,
+	)
+	require.Nil(t, err)
+	csr, err := x509.ParseCertificateRequest(der)
+	require.Nil(t, err)
+
+	actual, err := VerifyCSREAT(csr, csrChallenge, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, FormatCWT, actual.Format)
+
+	_, otherResolver := newTestSignerResolver(t)
+	_, err = VerifyCSREAT(csr, csrChallenge, otherResolver)
+	assert.ErrorContains(t, err, "verifying signature")
+
+	jwt, err := e.SignJWT(rand.Reader, signer)
+	require.Nil(t, err)
+
+	der, err = CreateCertificateRequestWithEAT(
+		rand.Reader, &x509.CertificateRequest{}, priv, MediaTypeJWT, []byte(jwt),
+	)
+	require.Nil(t, err)
+	csr, err = x509.ParseCertificateRequest(der)
+	require.Nil(t, err)
+
+	actual, err = VerifyCSREAT(csr, csrChallenge, resolver)
+	require.Nil(t, err)
+	assert.Equal(t, FormatJWT, actual.Format)
+
+	_, err = VerifyCSREAT(csr, csrChallenge, otherResolver)
+	assert.ErrorContains(t, err, "verification error")
+
+	csr = newCSRWithEAT(t, priv, e)
+	_, err = VerifyCSREAT(csr, csrChallenge, resolver)
+	assert.EqualError(t, err, "expecting a CWT or JWT to verify, found UCCS")
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"fmt"
+	"sort"
+
+	cbor "github.com/fxamacker/cbor/v2"
+)
+
+// RFC 9711 claim keys that have legacy equivalents
+const (
+	claimKeyNonce    = 10
+	claimKeyUEID     = 256
+	claimKeyOemID    = 258
+	claimKeyUptime   = 261
+	claimKeyOemBoot  = 262
+	claimKeyDbgStat  = 263
+	claimKeyLocation = 264
+	claimKeyProfile  = 265
+	claimKeySubmods  = 266
+)
+
+// legacyClaim describes how a claim key used by pre-RFC EAT drafts relates to
+// the RFC 9711 claim set
+type legacyClaim struct {
+	name string
+	// key is the RFC 9711 claim key, or zero if the legacy claim has no
+	// RFC 9711 equivalent and is therefore dropped on decoding
+	key int64
+	// note describes what is lost when the claim is mapped or dropped
+	note string
+	// check, if set, validates the legacy value before it is mapped
+	check func([]byte) error
+}
+
+// legacyClaims maps the claim keys used by pre-RFC drafts of EAT onto their
+// RFC 9711 equivalents.  Two generations are covered:
+//
+//   - the provisional negative keys shared with early PSA tokens (e.g., -75008
+//     for the nonce and -75009 for the UEID)
+//   - the interim small integer keys (11 to 20) of the EAT drafts preceding
+//     the allocation of the RFC 9711 keys
+//
+// The values of the mapped claims are encoded as in RFC 9711, except that
+// secboot asserted secure boot rather than OEM-authorized boot.  The nonce kept
+// key 10 across all drafts and is therefore not listed.
+var legacyClaims = map[int64]legacyClaim{
+	-75008: {name: "nonce", key: claimKeyNonce},
+	-75009: {name: "ueid", key: claimKeyUEID},
+	11:     {name: "ueid", key: claimKeyUEID},
+	12:     {name: "origination", note: "no RFC 9711 equivalent, dropped"},
+	13:     {name: "oemid", key: claimKeyOemID},
+	14:     {name: "seclevel", note: "no RFC 9711 equivalent, dropped"},
+	15: {
+		name:  "secboot",
+		key:   claimKeyOemBoot,
+		note:  "secure boot state reported as OEM-authorized boot",
+		check: checkLegacyBool,
+	},
+	16: {name: "dbgstat", key: claimKeyDbgStat},
+	17: {name: "location", key: claimKeyLocation},
+	18: {name: "eat_profile", key: claimKeyProfile},
+	19: {name: "uptime", key: claimKeyUptime},
+	20: {name: "submods", key: claimKeySubmods},
+}
+
+func checkLegacyBool(data []byte) error {
+	var b bool
+	return dm.Unmarshal(data, &b)
+}
+
+// legacyEncodeKeys maps RFC 9711 claim keys onto the interim keys emitted when
+// legacy CBOR encoding is requested
+var legacyEncodeKeys = map[int64]int64{
+	claimKeyUEID:     11,
+	claimKeyOemID:    13,
+	claimKeyOemBoot:  15,
+	claimKeyDbgStat:  16,
+	claimKeyLocation: 17,
+	claimKeyProfile:  18,
+	claimKeyUptime:   19,
+	claimKeySubmods:  20,
+}
+
+// LegacyClaim reports a pre-RFC construct found while decoding a token
+type LegacyClaim struct {
+	// Key is the legacy claim key as found in the token
+	Key int64
+	// Name is the name of the claim
+	Name string
+	// MappedTo is the RFC 9711 claim key the claim was mapped onto, or zero
+	// if it has no RFC 9711 equivalent and was dropped
+	MappedTo int64
+	// Value is the CBOR encoded value of a dropped claim, nil otherwise
+	Value []byte
+	// Note describes what was lost in mapping or dropping the claim, if
+	// anything
+	Note string
+	// Submod is the path of submod names leading to the claims-set where the
+	// construct was found, empty for the top-level claims-set
+	Submod []string
+}
+
+// CBOROptions controls the CBOR serialization of an Eat
+type CBOROptions struct {
+	// Legacy enables the pre-RFC claim layout.  On decoding, the provisional
+	// and interim claim keys used by earlier EAT drafts are mapped onto the
+	// current Eat fields.  On encoding, the interim claim keys are emitted in
+	// place of the RFC 9711 ones.
+	Legacy bool
+}
+
+// FromCBORWithOptions deserializes the supplied CBOR encoded EAT into the
+// receiver Eat according to the supplied options.  When legacy decoding is
+// enabled, the returned slice lists the legacy constructs that were found.
+func (e *Eat) FromCBORWithOptions(data []byte, opts CBOROptions) ([]LegacyClaim, error) {
+	if !opts.Legacy {
+		return nil, e.FromCBOR(data)
+	}
+
+	var seen []LegacyClaim
+
+	mapped, err := mapLegacyClaims(data, nil, &seen)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.FromCBOR(mapped); err != nil {
+		return nil, err
+	}
+
+	return seen, nil
+}
+
+// ToCBORWithOptions serializes the receiver Eat into CBOR encoded EAT
+// according to the supplied options
+//
+//nolint:gocritic
+func (e Eat) ToCBORWithOptions(opts CBOROptions) ([]byte, error) {
+	data, err := e.ToCBOR()
+	if err != nil || !opts.Legacy {
+		return data, err
+	}
+
+	return toLegacyClaims(data)
+}
+
+func mapLegacyClaims(data []byte, path []string, seen *[]LegacyClaim) ([]byte, error) {
+	var claims map[int64]cbor.RawMessage
+	if err := dm.Unmarshal(data, &claims); err != nil {
+		return nil, fmt.Errorf("CBOR decoding of claims-set failed: %w", err)
+	}
+
+	// process keys in a stable order so that the report is deterministic
+	keys := make([]int64, 0, len(claims))
+	for k := range claims {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, k := range keys {
+		lc, ok := legacyClaims[k]
+		if !ok {
+			continue
+		}
+
+		v := claims[k]
+		delete(claims, k)
+
+		c := LegacyClaim{
+			Key:      k,
+			Name:     lc.name,
+			MappedTo: lc.key,
+			Note:     lc.note,
+			Submod:   path,
+		}
+
+		if lc.key == 0 {
+			c.Value = v
+			*seen = append(*seen, c)
+			continue
+		}
+
+		*seen = append(*seen, c)
+
+		if lc.check != nil {
+			if err := lc.check(v); err != nil {
+				return nil, fmt.Errorf("legacy claim %s (%d): %w", lc.name, k, err)
+			}
+		}
+
+		if _, dup := claims[lc.key]; dup {
+			return nil, fmt.Errorf(
+				"legacy claim %s (%d) conflicts with claim %d", lc.name, k, lc.key,
+			)
+		}
+
+		claims[lc.key] = v
+	}
+
+	if v, ok := claims[claimKeySubmods]; ok {
+		mapped, err := mapSubmods(v, func(name string, sm []byte) ([]byte, error) {
+			return mapLegacyClaims(sm, append(append([]string(nil), path...), name), seen)
+		})
+		if err != nil {
+			return nil, err
+		}
+		claims[claimKeySubmods] = mapped
+	}
+
+	return em.Marshal(claims)
+}
+
+func toLegacyClaims(data []byte) ([]byte, error) {
+	var claims map[int64]cbor.RawMessage
+	if err := dm.Unmarshal(data, &claims); err != nil {
+		return nil, fmt.Errorf("CBOR decoding of claims-set failed: %w", err)
+	}
+
+	if v, ok := claims[claimKeySubmods]; ok {
+		mapped, err := mapSubmods(v, func(_ string, sm []byte) ([]byte, error) {
+			return toLegacyClaims(sm)
+		})
+		if err != nil {
+			return nil, err
+		}
+		claims[claimKeySubmods] = mapped
+	}
+
+	legacy := make(map[int64]cbor.RawMessage, len(claims))
+	for k, v := range claims {
+		if lk, ok := legacyEncodeKeys[k]; ok {
+			k = lk
+		}
+		legacy[k] = v
+	}
+
+	return em.Marshal(legacy)
+}
+
+// mapSubmods applies fn to each claims-set submod found in the supplied
+// submods claim, leaving nested tokens untouched
+func mapSubmods(data []byte, fn func(string, []byte) ([]byte, error)) ([]byte, error) {
+	var submods map[string]cbor.RawMessage
+	if err := dm.Unmarshal(data, &submods); err != nil {
+		return nil, fmt.Errorf("CBOR decoding of submods failed: %w", err)
+	}
+
+	names := make([]string, 0, len(submods))
+	for name := range submods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sm := submods[name]
+		if len(sm) == 0 || isCBORByteString(sm) {
+			continue
+		}
+
+		mapped, err := fn(name, sm)
+		if err != nil {
+			return nil, fmt.Errorf("submod %s: %w", name, err)
+		}
+
+		submods[name] = mapped
+	}
+
+	return em.Marshal(submods)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEat_FromCBORWithOptions_ProvisionalKeys(t *testing.T) {
+	// {-75008: h'0000000000000000', -75009: h'01deadbeefdeadbeefdeadbeefdeadbeef', 14: 3}
+	tv := []byte{
+		0xa3, 0x0e, 0x03, 0x3a, 0x00, 0x01, 0x24, 0xff, 0x48, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x3a, 0x00, 0x01, 0x25, 0x00, 0x51, 0x01,
+		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef,
+		0xde, 0xad, 0xbe, 0xef,
+	}
+
+	var actual Eat
+	seen, err := actual.FromCBORWithOptions(tv, CBOROptions{Legacy: true})
+	require.Nil(t, err)
+
+	assert.Equal(t, Eat{Nonce: &Nonce{nonce{nonceBytes}}, UEID: &ueID}, actual)
+	assert.Equal(t, []LegacyClaim{
+		{Key: -75009, Name: "ueid", MappedTo: 256},
+		{Key: -75008, Name: "nonce", MappedTo: 10},
+		{Key: 14, Name: "seclevel", MappedTo: 0, Value: []byte{0x03}, Note: "no RFC 9711 equivalent, dropped"},
+	}, seen)
+
+	// without the legacy option the provisional claims are silently ignored
+	actual = Eat{}
+	seen, err = actual.FromCBORWithOptions(tv, CBOROptions{})
+	require.Nil(t, err)
+	assert.Nil(t, seen)
+	assert.Equal(t, Eat{}, actual)
+}
+
+func TestEat_CBORWithOptions_LegacyRoundTrip(t *testing.T) {
+	var inner Submods
+	require.Nil(t, inner.Add("xyz", []byte{0xd8, 0x3d, 0xd2, 0x41, 0xa0}))
+
+	var outer Submods
+	require.Nil(t, outer.Add("0", Eat{UEID: &ueID, Submods: &inner}))
+
+	tv := Eat{
+		Nonce:       &Nonce{nonce{nonceBytes}},
+		OemBoot:     &oemBoot,
+		DebugStatus: &debug,
+		Uptime:      &uptime,
+		Submods:     &outer,
+	}
+
+	data, err := tv.ToCBORWithOptions(CBOROptions{Legacy: true})
+	require.Nil(t, err)
+
+	// {10: h'0000000000000000', 15: true, 16: 1, 19: 60,
+	//  20: {"0": {11: h'01deadbeefdeadbeefdeadbeefdeadbeef', 20: {"xyz": h'd83dd241a0'}}}}
+	expected := []byte{
+		0xa5, 0x0a, 0x48, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0f,
+		0xf5, 0x10, 0x01, 0x13, 0x18, 0x3c, 0x14, 0xa1, 0x61, 0x30, 0xa2, 0x0b,
+		0x51, 0x01, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad,
+		0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef, 0x14, 0xa1, 0x63, 0x78, 0x79, 0x7a,
+		0x45, 0xd8, 0x3d, 0xd2, 0x41, 0xa0,
+	}
+	assert.Equal(t, expected, data)
+
+	var actual Eat
+	seen, err := actual.FromCBORWithOptions(data, CBOROptions{Legacy: true})
+	require.Nil(t, err)
+	assert.Equal(t, tv, actual)
+	assert.Equal(t, []LegacyClaim{
+		{Key: 15, Name: "secboot", MappedTo: 262, Note: "secure boot state reported as OEM-authorized boot"},
+		{Key: 16, Name: "dbgstat", MappedTo: 263},
+		{Key: 19, Name: "uptime", MappedTo: 261},
+		{Key: 20, Name: "submods", MappedTo: 266},
+		{Key: 11, Name: "ueid", MappedTo: 256, Submod: []string{"0"}},
+		{Key: 20, Name: "submods", MappedTo: 266, Submod: []string{"0"}},
+	}, seen)
+
+	// the default encoding is unaffected
+	data, err = tv.ToCBORWithOptions(CBOROptions{})
+	require.Nil(t, err)
+	expected, err = tv.ToCBOR()
+	require.Nil(t, err)
+	assert.Equal(t, expected, data)
+}
+
+func TestEat_FromCBORWithOptions_Conflict(t *testing.T) {
+	// {11: h'01deadbeefdeadbeefdeadbeefdeadbeef', 256: h'01deadbeefdeadbeefdeadbeefdeadbeef'}
+	tv := []byte{
+		0xa2, 0x0b, 0x51, 0x01, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef,
+		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef, 0x19, 0x01, 0x00, 0x51,
+		0x01, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe,
+		0xef, 0xde, 0xad, 0xbe, 0xef,
+	}
+
+	var actual Eat
+	_, err := actual.FromCBORWithOptions(tv, CBOROptions{Legacy: true})
+	assert.EqualError(t, err, "legacy claim ueid (11) conflicts with claim 256")
+
+	// {15: 1}
+	_, err = actual.FromCBORWithOptions([]byte{0xa1, 0x0f, 0x01}, CBOROptions{Legacy: true})
+	assert.ErrorContains(t, err, "legacy claim secboot (15): ")
+}
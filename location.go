@@ -3,6 +3,12 @@
 
 package eat
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
 /*
 =======
 location-type = {
@@ -51,3 +57,139 @@ type Location struct {
 	Timestamp        *NumericDate `cbor:"8,keyasint,omitempty" json:"timestamp,omitempty"`
 	Age              *uint        `cbor:"9,keyasint,omitempty" json:"age,omitempty"`
 }
+
+// Validate checks that the receiver Location is within the ranges defined by
+// the W3C Geolocation API, which RFC 9711 refers to: latitude in [-90, 90],
+// longitude in [-180, 180], heading in [0, 360), and non-negative accuracy,
+// altitude accuracy and speed.  All values must be finite.
+func (l Location) Validate() error {
+	if err := checkRange("latitude", l.Latitude, -90, 90); err != nil {
+		return err
+	}
+
+	if err := checkRange("longitude", l.Longitude, -180, 180); err != nil {
+		return err
+	}
+
+	if l.Altitude != nil && !isFinite(*l.Altitude) {
+		return fmt.Errorf("altitude must be finite, found %v", *l.Altitude)
+	}
+
+	for _, f := range []struct {
+		name string
+		v    *Number
+	}{
+		{"accuracy", l.Accuracy},
+		{"altitude accuracy", l.AltitudeAccuracy},
+		{"speed", l.Speed},
+	} {
+		if f.v != nil {
+			if err := checkRange(f.name, *f.v, 0, math.MaxFloat64); err != nil {
+				return err
+			}
+		}
+	}
+
+	if l.Heading != nil {
+		h := *l.Heading
+		if !isFinite(h) || h < 0 || h >= 360 {
+			return fmt.Errorf("heading must be in range [0, 360), found %v", h)
+		}
+	}
+
+	return nil
+}
+
+func isFinite(n Number) bool {
+	f := float64(n)
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func checkRange(name string, n Number, lo, hi float64) error {
+	f := float64(n)
+	if !isFinite(n) || f < lo || f > hi {
+		if hi == math.MaxFloat64 {
+			return fmt.Errorf("%s must be non-negative, found %v", name, f)
+		}
+		return fmt.Errorf("%s must be in range [%v, %v], found %v", name, lo, hi, f)
+	}
+	return nil
+}
+
+// EarthRadius is the mean radius of the Earth in metres, as defined by the
+// IUGG, which is used for great-circle distance computations
+const EarthRadius = 6371008.8
+
+// DistanceTo returns the great-circle distance in metres between the receiver
+// and the supplied Location, computed with the haversine formula.  Altitude is
+// ignored.
+func (l Location) DistanceTo(other Location) float64 {
+	lat1 := degToRad(float64(l.Latitude))
+	lat2 := degToRad(float64(other.Latitude))
+	dLat := lat2 - lat1
+	dLon := degToRad(float64(other.Longitude - l.Longitude))
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func degToRad(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+// Within returns true if the receiver Location is inside the supplied
+// Geofence and its accuracy is known and no worse (i.e., no larger) than
+// maxAccuracy metres.  A Location that fails validation is never within any
+// region.
+func (l Location) Within(g Geofence, maxAccuracy float64) bool {
+	if l.Validate() != nil {
+		return false
+	}
+
+	if l.Accuracy == nil || float64(*l.Accuracy) > maxAccuracy {
+		return false
+	}
+
+	return g.Contains(l)
+}
+
+// ToGeoJSON serializes the receiver Location as a GeoJSON (RFC 7946) Feature
+// with a Point geometry.  The optional location fields are carried in the
+// Feature properties using their JSON claim names.
+func (l Location) ToGeoJSON() ([]byte, error) {
+	coords := []float64{float64(l.Longitude), float64(l.Latitude)}
+	if l.Altitude != nil {
+		coords = append(coords, float64(*l.Altitude))
+	}
+
+	props := map[string]interface{}{}
+	if l.Accuracy != nil {
+		props["accry"] = *l.Accuracy
+	}
+	if l.AltitudeAccuracy != nil {
+		props["alt-accry"] = *l.AltitudeAccuracy
+	}
+	if l.Heading != nil {
+		props["heading"] = *l.Heading
+	}
+	if l.Speed != nil {
+		props["speed"] = *l.Speed
+	}
+	if l.Timestamp != nil {
+		props["timestamp"] = *l.Timestamp
+	}
+	if l.Age != nil {
+		props["age"] = *l.Age
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"type": "Feature",
+		"geometry": map[string]interface{}{
+			"type":        "Point",
+			"coordinates": coords,
+		},
+		"properties": props,
+	})
+}
@@ -5,6 +5,7 @@ package eat
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -272,3 +273,97 @@ func TestLocation_Unmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestLocation_Validate(t *testing.T) {
+	neg := Number(-1)
+	nan := Number(math.NaN())
+	full := Number(360)
+
+	tests := []struct {
+		name     string
+		tv       Location
+		expected string
+	}{
+		{"ok", Location{Latitude: 45, Longitude: -179.5}, ""},
+		{"latitude too big", Location{Latitude: 90.1}, "latitude must be in range [-90, 90], found 90.1"},
+		{"longitude too small", Location{Longitude: -181}, "longitude must be in range [-180, 180], found -181"},
+		{"NaN latitude", Location{Latitude: nan}, "latitude must be in range [-90, 90], found NaN"},
+		{"negative accuracy", Location{Accuracy: &neg}, "accuracy must be non-negative, found -1"},
+		{"negative altitude accuracy", Location{AltitudeAccuracy: &neg}, "altitude accuracy must be non-negative, found -1"},
+		{"negative speed", Location{Speed: &neg}, "speed must be non-negative, found -1"},
+		{"heading 360", Location{Heading: &full}, "heading must be in range [0, 360), found 360"},
+		{"NaN altitude", Location{Altitude: &nan}, "altitude must be finite, found NaN"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.tv.Validate()
+			if test.expected == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestLocation_DistanceTo(t *testing.T) {
+	// one degree along a meridian is 2*pi*R/360
+	a := Location{Latitude: 10, Longitude: 20}
+	b := Location{Latitude: 11, Longitude: 20}
+	assert.InDelta(t, 2*math.Pi*EarthRadius/360, a.DistanceTo(b), 1e-6)
+
+	// Cambridge (UK) to Paris, roughly 404km
+	cambridge := Location{Latitude: 52.2053, Longitude: 0.1218}
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+
+	assert.InDelta(t, 404000, cambridge.DistanceTo(paris), 1000)
+	assert.InDelta(t, cambridge.DistanceTo(paris), paris.DistanceTo(cambridge), 1e-6)
+	assert.Equal(t, float64(0), paris.DistanceTo(paris))
+}
+
+func TestLocation_Within(t *testing.T) {
+	fine := Number(10)
+	coarse := Number(500)
+
+	circle := GeoCircle{Center: Location{Latitude: 52.2053, Longitude: 0.1218}, Radius: 1000}
+	square := GeoPolygon{
+		{Latitude: 52.20, Longitude: 0.11},
+		{Latitude: 52.20, Longitude: 0.13},
+		{Latitude: 52.21, Longitude: 0.13},
+		{Latitude: 52.21, Longitude: 0.11},
+	}
+
+	inside := Location{Latitude: 52.2060, Longitude: 0.1220, Accuracy: &fine}
+	assert.True(t, inside.Within(circle, 50))
+	assert.True(t, inside.Within(square, 50))
+
+	imprecise := inside
+	imprecise.Accuracy = &coarse
+	assert.False(t, imprecise.Within(circle, 50))
+
+	unknownAccuracy := inside
+	unknownAccuracy.Accuracy = nil
+	assert.False(t, unknownAccuracy.Within(circle, 50))
+
+	outside := Location{Latitude: 52.2300, Longitude: 0.1218, Accuracy: &fine}
+	assert.False(t, outside.Within(circle, 50))
+	assert.False(t, outside.Within(square, 50))
+
+	assert.False(t, inside.Within(GeoPolygon{square[0], square[1]}, 50))
+}
+
+func TestLocation_ToGeoJSON(t *testing.T) {
+	alt := Number(12.5)
+	accry := Number(3)
+
+	l := Location{Latitude: 52.2053, Longitude: 0.1218, Altitude: &alt, Accuracy: &accry}
+
+	actual, err := l.ToGeoJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [0.1218, 52.2053, 12.5]},
+		"properties": {"accry": 3}
+	}`, string(actual))
+}
@@ -3,8 +3,28 @@
 
 package eat
 
+import "encoding/json"
+
 type Manifest struct {
 	_      struct{} `cbor:",toarray"` // TODO: implement Unmarshal.JSON
 	Type   int      // coap-content-format, see https://www.iana.org/assignments/core-parameters/core-parameters.xhtml
 	Format []byte   // bstr wrapped untagged-coswid, ...
 }
+
+// MarshalJSON encodes the receiver Manifest, with Format as base64url
+func (m Manifest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatJSON{m.Type, m.Format})
+}
+
+// UnmarshalJSON decodes the supplied JSON data into the receiver Manifest.
+// Format may be encoded using either base64url or standard base64.
+func (m *Manifest) UnmarshalJSON(data []byte) error {
+	var f formatJSON
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	m.Type, m.Format = f.Type, f.Format
+
+	return nil
+}
@@ -4,19 +4,268 @@
 package eat
 
 import (
-	"github.com/veraison/swid"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 )
 
+/*
+measured-component = {
+
+	id-label => component-id,
+	measurement,
+	? signers-label => [ + signer ],
+	? flags-label => flags-type
+
+}
+
+measurement //= ( digested-measurement-label => digest )
+measurement //= ( raw-measurement-label => bytes )
+
+component-id = [
+
+	name: text,
+	? version: version
+
+]
+
+signer = eat.binary-data
+flags-type = eat.binary-data
+
+id-label = 1
+digested-measurement-label = 2
+signers-label = 3
+flags-label = 4
+raw-measurement-label = 5
+*/
+
+// MeasuredComponent models the measured-component type defined in
+// draft-ietf-rats-eat-measured-component.  Tokens produced according to draft
+// -05 remain decodable (see Digest).
 type MeasuredComponent struct {
-	Id             ComponentID     `cbor:"1,keyasint" json:"id"`
-	Measurement    *swid.HashEntry `cbor:"2,keyasint,omitempty" json:"measurement,omitempty"`
-	Signers        *[][]byte       `cbor:"3,keyasint,omitempty" json:"signers,omitempty"`
-	Flags          *[]byte         `cbor:"4,keyasint,omitempty" json:"flags,omitempty"`
-	RawMeasurement *[]byte         `cbor:"5,keyasint,omitempty" json:"raw-measurement,omitempty"`
+	Id             ComponentID `cbor:"1,keyasint" json:"id"`
+	Measurement    *Digest     `cbor:"2,keyasint,omitempty" json:"measurement,omitempty"`
+	Signers        *[][]byte   `cbor:"3,keyasint,omitempty" json:"signers,omitempty"`
+	Flags          *[]byte     `cbor:"4,keyasint,omitempty" json:"flags,omitempty"`
+	RawMeasurement *[]byte     `cbor:"5,keyasint,omitempty" json:"raw-measurement,omitempty"`
 }
 
+// ComponentID identifies a measured component by name and, optionally, version
 type ComponentID struct {
 	_       struct{} `cbor:",toarray"`
 	Name    string   `cbor:"0,keyasint"`
 	Version *Version `cbor:"1,keyasint,omitempty"`
 }
+
+// Validate checks that the receiver ComponentID has a non-empty name
+func (c ComponentID) Validate() error {
+	if c.Name == "" {
+		return errors.New("empty component name")
+	}
+	return nil
+}
+
+// MarshalJSON encodes the receiver ComponentID as a JSON array
+func (c ComponentID) MarshalJSON() ([]byte, error) {
+	r := []interface{}{c.Name}
+	if c.Version != nil {
+		r = append(r, *c.Version)
+	}
+	return json.Marshal(r)
+}
+
+// UnmarshalJSON decodes a JSON array [name, ? version] into the receiver
+// ComponentID
+func (c *ComponentID) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw) < 1 || len(raw) > 2 {
+		return fmt.Errorf("invalid component ID JSON array length: %d", len(raw))
+	}
+
+	if err := json.Unmarshal(raw[0], &c.Name); err != nil {
+		return fmt.Errorf("invalid component name: expected string")
+	}
+
+	c.Version = nil
+	if len(raw) == 1 {
+		return nil
+	}
+
+	var v Version
+	if err := json.Unmarshal(raw[1], &v); err != nil {
+		return fmt.Errorf("invalid component version: %w", err)
+	}
+	c.Version = &v
+
+	return nil
+}
+
+// Validate checks that the receiver MeasuredComponent is well-formed: the
+// component ID has a name, exactly one of the digested and raw measurements is
+// present, the digest length matches its algorithm, and signers and flags, if
+// present, are non-empty
+func (mc MeasuredComponent) Validate() error {
+	if err := mc.Id.Validate(); err != nil {
+		return fmt.Errorf("invalid component ID: %w", err)
+	}
+
+	switch {
+	case mc.Measurement == nil && mc.RawMeasurement == nil:
+		return errors.New("no measurement in measured component")
+	case mc.Measurement != nil && mc.RawMeasurement != nil:
+		return errors.New("both digested and raw measurement in measured component")
+	case mc.Measurement != nil:
+		if err := mc.Measurement.Validate(); err != nil {
+			return fmt.Errorf("invalid measurement: %w", err)
+		}
+	}
+
+	if mc.Signers != nil {
+		if len(*mc.Signers) == 0 {
+			return errors.New("empty signers")
+		}
+		for i, s := range *mc.Signers {
+			if len(s) == 0 {
+				return fmt.Errorf("empty signer at index %d", i)
+			}
+		}
+	}
+
+	if mc.Flags != nil && len(*mc.Flags) == 0 {
+		return errors.New("empty flags")
+	}
+
+	return nil
+}
+
+// ToMeasurement returns a Measurement, for use in the Measurements claim,
+// carrying the CBOR encoding of the receiver MeasuredComponent with the
+// supplied CoAP content-format
+func (mc MeasuredComponent) ToMeasurement(contentFormat int) (*Measurement, error) {
+	if err := mc.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := em.Marshal(mc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Measurement{Type: contentFormat, Format: data}, nil
+}
+
+// MeasuredComponentOption sets optional fields of a MeasuredComponent when it
+// is created using one of the NewMeasuredComponentFrom* constructors
+type MeasuredComponentOption func(*MeasuredComponent)
+
+// WithSigners sets the identifiers of the entities that signed the measured
+// component
+func WithSigners(signers ...[]byte) MeasuredComponentOption {
+	return func(mc *MeasuredComponent) {
+		s := append([][]byte(nil), signers...)
+		mc.Signers = &s
+	}
+}
+
+// WithFlags sets the flags associated with the measured component
+func WithFlags(flags []byte) MeasuredComponentOption {
+	return func(mc *MeasuredComponent) {
+		f := append([]byte(nil), flags...)
+		mc.Flags = &f
+	}
+}
+
+// NewMeasuredComponentFromBytes returns a MeasuredComponent with the supplied
+// id, whose measurement is the digest of data computed using the hash algorithm
+// identified by algID (see the IANA Named Information Hash Algorithm Registry)
+func NewMeasuredComponentFromBytes(
+	id ComponentID, algID uint64, data []byte, opts ...MeasuredComponentOption,
+) (*MeasuredComponent, error) {
+	return NewMeasuredComponentFromReader(id, algID, bytes.NewReader(data), opts...)
+}
+
+// NewMeasuredComponentFromReader is like NewMeasuredComponentFromBytes except
+// the measured content is read from r until EOF
+func NewMeasuredComponentFromReader(
+	id ComponentID, algID uint64, r io.Reader, opts ...MeasuredComponentOption,
+) (*MeasuredComponent, error) {
+	digest, err := computeDigest(algID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Digest{Alg: algID, Value: digest}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	mc := MeasuredComponent{
+		Id:          id,
+		Measurement: &d,
+	}
+
+	for _, opt := range opts {
+		opt(&mc)
+	}
+
+	return &mc, nil
+}
+
+// NewMeasuredComponentFromFile is like NewMeasuredComponentFromBytes except
+// the measured content is the contents of the file at path
+func NewMeasuredComponentFromFile(
+	id ComponentID, algID uint64, path string, opts ...MeasuredComponentOption,
+) (*MeasuredComponent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewMeasuredComponentFromReader(id, algID, f, opts...)
+}
+
+// Verify recomputes the digest of the supplied content using the hash
+// algorithm of the receiver's measurement and checks that it matches the
+// measured value
+func (mc MeasuredComponent) Verify(content []byte) error {
+	return mc.VerifyReader(bytes.NewReader(content))
+}
+
+// VerifyReader is like Verify except the content is read from r until EOF
+func (mc MeasuredComponent) VerifyReader(r io.Reader) error {
+	if mc.Measurement == nil {
+		return errors.New("no measurement in measured component")
+	}
+
+	digest, err := computeDigest(mc.Measurement.Alg, r)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(digest, mc.Measurement.Value) {
+		return fmt.Errorf("measurement mismatch for component %q", mc.Id.Name)
+	}
+
+	return nil
+}
+
+func computeDigest(algID uint64, r io.Reader) ([]byte, error) {
+	h, err := newHash(algID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, fmt.Errorf("reading measured content: %w", err)
+	}
+
+	return h.Sum(nil), nil
+}
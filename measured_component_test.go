@@ -1,9 +1,17 @@
 package eat
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	cbor "github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/swid"
 )
 
 func TestMeasuredComponent(t *testing.T) {
@@ -29,3 +37,185 @@ func TestMeasuredComponent(t *testing.T) {
 	assert.Equal(t, mc.Id.Name, "Foo")
 	assert.Equal(t, mc.Id.Version.Version, "1.3.4")
 }
+
+func TestMeasuredComponent_NewFromBytes_OK(t *testing.T) {
+	content := []byte("hello world")
+	id := ComponentID{Name: "Foo"}
+
+	// echo -n "hello world" | sha256sum
+	expected, _ := hex.DecodeString("b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")
+
+	mc, err := NewMeasuredComponentFromBytes(
+		id, swid.Sha256, content,
+		WithSigners([]byte{0x01, 0x02}),
+		WithFlags([]byte{0x80}),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, "Foo", mc.Id.Name)
+	assert.Equal(t, swid.Sha256, mc.Measurement.Alg)
+	assert.Equal(t, expected, mc.Measurement.Value)
+	assert.Equal(t, [][]byte{{0x01, 0x02}}, *mc.Signers)
+	assert.Equal(t, []byte{0x80}, *mc.Flags)
+
+	assert.Nil(t, mc.Verify(content))
+	assert.EqualError(t, mc.Verify([]byte("hello world!")), `measurement mismatch for component "Foo"`)
+}
+
+func TestMeasuredComponent_NewFromBytes_AllAlgorithms(t *testing.T) {
+	for _, algID := range []uint64{
+		swid.Sha256, swid.Sha384, swid.Sha512,
+		swid.Sha3_224, swid.Sha3_256, swid.Sha3_384, swid.Sha3_512,
+	} {
+		mc, err := NewMeasuredComponentFromBytes(ComponentID{Name: "Foo"}, algID, []byte("x"))
+		require.Nil(t, err, "alg %d", algID)
+		assert.Nil(t, swid.ValidHashEntry(algID, mc.Measurement.Value))
+		assert.Nil(t, mc.Verify([]byte("x")))
+	}
+}
+
+func TestMeasuredComponent_NewFromBytes_UnsupportedAlg(t *testing.T) {
+	_, err := NewMeasuredComponentFromBytes(ComponentID{Name: "Foo"}, swid.Sha256_128, []byte("x"))
+	assert.EqualError(t, err, "unsupported hash algorithm 2")
+}
+
+func TestMeasuredComponent_NewFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob")
+	require.Nil(t, os.WriteFile(path, []byte("hello world"), 0600))
+
+	mc, err := NewMeasuredComponentFromFile(ComponentID{Name: "blob"}, swid.Sha384, path)
+	require.Nil(t, err)
+	assert.Nil(t, mc.VerifyReader(strings.NewReader("hello world")))
+
+	_, err = NewMeasuredComponentFromFile(ComponentID{Name: "blob"}, swid.Sha384, path+".missing")
+	assert.NotNil(t, err)
+}
+
+func TestMeasuredComponent_Verify_NoMeasurement(t *testing.T) {
+	mc := MeasuredComponent{Id: ComponentID{Name: "Foo"}}
+	assert.EqualError(t, mc.Verify(nil), "no measurement in measured component")
+}
+
+func TestMeasuredComponent_Validate(t *testing.T) {
+	digest := Digest{Alg: swid.Sha256, Value: make([]byte, 32)}
+	raw := []byte{0x00}
+	empty := []byte{}
+	emptySigners := [][]byte{}
+	badSigners := [][]byte{{0x01}, {}}
+
+	tests := []struct {
+		name     string
+		tv       MeasuredComponent
+		expected string
+	}{
+		{"ok digest", MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &digest}, ""},
+		{"ok raw", MeasuredComponent{Id: ComponentID{Name: "Foo"}, RawMeasurement: &raw}, ""},
+		{
+			"no name",
+			MeasuredComponent{Measurement: &digest},
+			"invalid component ID: empty component name",
+		},
+		{
+			"no measurement",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}},
+			"no measurement in measured component",
+		},
+		{
+			"both measurements",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &digest, RawMeasurement: &raw},
+			"both digested and raw measurement in measured component",
+		},
+		{
+			"bad digest length",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &Digest{Alg: swid.Sha384, Value: make([]byte, 32)}},
+			"invalid measurement: length mismatch for hash algorithm sha-384: want 48 bytes, got 32",
+		},
+		{
+			"empty signers",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &digest, Signers: &emptySigners},
+			"empty signers",
+		},
+		{
+			"empty signer",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &digest, Signers: &badSigners},
+			"empty signer at index 1",
+		},
+		{
+			"empty flags",
+			MeasuredComponent{Id: ComponentID{Name: "Foo"}, Measurement: &digest, Flags: &empty},
+			"empty flags",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.tv.Validate()
+			if test.expected == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestMeasuredComponent_ToMeasurement(t *testing.T) {
+	mc, err := NewMeasuredComponentFromBytes(ComponentID{Name: "Foo"}, swid.Sha256, []byte("x"))
+	require.Nil(t, err)
+
+	m, err := mc.ToMeasurement(65000)
+	require.Nil(t, err)
+	assert.Equal(t, 65000, m.Type)
+
+	var actual MeasuredComponent
+	require.Nil(t, cbor.Unmarshal(m.Format, &actual))
+	assert.Equal(t, *mc, actual)
+
+	_, err = MeasuredComponent{}.ToMeasurement(65000)
+	assert.EqualError(t, err, "invalid component ID: empty component name")
+}
+
+func TestMeasuredComponent_JSON_RoundTrip(t *testing.T) {
+	mc, err := NewMeasuredComponentFromBytes(
+		ComponentID{Name: "Foo", Version: &Version{Version: "1.3.4"}},
+		swid.Sha256, []byte("hello world"),
+	)
+	require.Nil(t, err)
+
+	data, err := json.Marshal(mc)
+	require.Nil(t, err)
+	assert.JSONEq(t,
+		`{"id":["Foo",["1.3.4"]],"measurement":[1,"uU0nuZNNPgilLlLX2n2r-sSE7-N6U4DukIj3rOLvzek"]}`,
+		string(data),
+	)
+
+	var actual MeasuredComponent
+	require.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, *mc, actual)
+}
+
+func TestMeasuredComponent_JSON_DraftV05(t *testing.T) {
+	tv := `{"id":["Foo"],"measurement":"sha-256:uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="}`
+
+	var mc MeasuredComponent
+	require.Nil(t, json.Unmarshal([]byte(tv), &mc))
+	assert.Equal(t, swid.Sha256, mc.Measurement.Alg)
+	assert.Nil(t, mc.Verify([]byte("hello world")))
+}
+
+func TestDigest_CBOR_TextAlg(t *testing.T) {
+	// [ "sha-256", h'0000000000000000000000000000000000000000000000000000000000000000' ]
+	tv := append([]byte{0x82, 0x67, 's', 'h', 'a', '-', '2', '5', '6', 0x58, 0x20}, make([]byte, 32)...)
+
+	var d Digest
+	require.Nil(t, dm.Unmarshal(tv, &d))
+	assert.Equal(t, swid.Sha256, d.Alg)
+	assert.Nil(t, d.Validate())
+
+	// re-encoded using the integer form
+	encoded, err := em.Marshal(d)
+	require.Nil(t, err)
+	assert.Equal(t, append([]byte{0x82, 0x01, 0x58, 0x20}, make([]byte, 32)...), encoded)
+
+	tv[3] = 'x'
+	assert.EqualError(t, dm.Unmarshal(tv, &d), `unknown hash algorithm "sxa-256"`)
+}
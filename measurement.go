@@ -3,8 +3,35 @@
 
 package eat
 
+import "encoding/json"
+
 type Measurement struct {
 	_      struct{} `cbor:",toarray"` // TODO: implement Unmarshal.JSON
 	Type   int      // coap-content-format, see https://www.iana.org/assignments/core-parameters/core-parameters.xhtml
 	Format []byte   // bstr wrapped untagged-coswid, measured-component, ...
 }
+
+// formatJSON is the JSON encoding of Measurement and Manifest, with Format
+// encoded as base64url without padding
+type formatJSON struct {
+	Type   int
+	Format binaryData
+}
+
+// MarshalJSON encodes the receiver Measurement, with Format as base64url
+func (m Measurement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatJSON{m.Type, m.Format})
+}
+
+// UnmarshalJSON decodes the supplied JSON data into the receiver Measurement.
+// Format may be encoded using either base64url or standard base64.
+func (m *Measurement) UnmarshalJSON(data []byte) error {
+	var f formatJSON
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	m.Type, m.Format = f.Type, f.Format
+
+	return nil
+}
@@ -0,0 +1,318 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// EAT media types (RFC 9782)
+const (
+	MediaTypeCWT     = "application/eat+cwt"
+	MediaTypeJWT     = "application/eat+jwt"
+	MediaTypeUCCS    = "application/eat-ucs+cbor"
+	MediaTypeUJCS    = "application/eat-ucs+json"
+	MediaTypeDEBCBOR = "application/eat-bun+cbor"
+	MediaTypeDEBJSON = "application/eat-bun+json"
+)
+
+// CMW media types (draft-ietf-rats-msg-wrap)
+const (
+	MediaTypeCMWCBOR = "application/cmw+cbor"
+	MediaTypeCMWJSON = "application/cmw+json"
+)
+
+// ProfileParameter is the name of the media type parameter that carries the
+// EAT profile
+const ProfileParameter = "eat_profile"
+
+// contentFormats maps the CoAP content-formats registered for the EAT media
+// types onto the media types
+var contentFormats = map[uint16]string{
+	263: MediaTypeCWT,
+	264: MediaTypeJWT,
+	265: MediaTypeDEBCBOR,
+	266: MediaTypeDEBJSON,
+	267: MediaTypeUCCS,
+	268: MediaTypeUJCS,
+}
+
+// MediaType is an EAT media type, with its optional eat_profile parameter
+type MediaType struct {
+	// Type is one of the EAT media types, e.g., MediaTypeCWT
+	Type string
+	// Profile is the value of the eat_profile parameter, if any
+	Profile *Profile
+}
+
+// ParseMediaType parses the supplied string as an EAT media type.  The only
+// parameter allowed is eat_profile, whose value must be a valid profile.
+func ParseMediaType(s string) (*MediaType, error) {
+	base, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed media type %q: %w", s, err)
+	}
+
+	if _, ok := eatFormats[base]; !ok {
+		return nil, fmt.Errorf("%q is not an EAT media type", base)
+	}
+
+	mt := MediaType{Type: base}
+
+	for name, value := range params {
+		if name != ProfileParameter {
+			return nil, fmt.Errorf("unknown media type parameter %q", name)
+		}
+
+		p, err := NewProfile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", ProfileParameter, err)
+		}
+
+		mt.Profile = p
+	}
+
+	return &mt, nil
+}
+
+// MediaTypeFromContentFormat returns the EAT media type registered with the
+// supplied CoAP content-format
+func MediaTypeFromContentFormat(cf uint16) (*MediaType, error) {
+	t, ok := contentFormats[cf]
+	if !ok {
+		return nil, fmt.Errorf("content-format %d is not an EAT content-format", cf)
+	}
+	return &MediaType{Type: t}, nil
+}
+
+// String returns the receiver MediaType as a string, including the eat_profile
+// parameter if set
+func (m MediaType) String() string {
+	if m.Profile == nil {
+		return m.Type
+	}
+
+	id, err := m.Profile.Get()
+	if err != nil {
+		return m.Type
+	}
+
+	return mime.FormatMediaType(m.Type, map[string]string{ProfileParameter: id})
+}
+
+// ContentFormat returns the CoAP content-format registered for the receiver
+// MediaType.  Content-formats are only registered for the media types without
+// parameters.
+func (m MediaType) ContentFormat() (uint16, error) {
+	if m.Profile != nil {
+		return 0, fmt.Errorf("no content-format registered for %s", m)
+	}
+
+	cfs := make([]int, 0, len(contentFormats))
+	for cf := range contentFormats {
+		cfs = append(cfs, int(cf))
+	}
+	sort.Ints(cfs)
+
+	for _, cf := range cfs {
+		if contentFormats[uint16(cf)] == m.Type {
+			return uint16(cf), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no content-format registered for %s", m)
+}
+
+// Format returns the token format associated with the receiver MediaType
+func (m MediaType) Format() Format {
+	return eatFormats[m.Type]
+}
+
+// CheckProfile cross-checks the eat_profile parameter of the receiver
+// MediaType, if set, against the eat_profile claim of the supplied Eat
+//
+//nolint:gocritic
+func (m MediaType) CheckProfile(e *Eat) error {
+	if m.Profile == nil {
+		return nil
+	}
+
+	expected, err := m.Profile.Get()
+	if err != nil {
+		return err
+	}
+
+	if e.Profile == nil {
+		return fmt.Errorf("missing eat_profile claim, expecting %q", expected)
+	}
+
+	actual, err := e.Profile.Get()
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return fmt.Errorf(
+			"%s parameter %q does not match eat_profile claim %q",
+			ProfileParameter, expected, actual,
+		)
+	}
+
+	return nil
+}
+
+// MediaTypeOptions supplies the keys needed to produce and consume the signed
+// EAT media types
+type MediaTypeOptions struct {
+	// Signer signs the CWTs and JWTs produced by ToMediaTypeWithOptions
+	Signer cose.Signer
+	// SignOptions are passed to Sign or SignJWT
+	SignOptions []SignOption
+	// Rand is the source of randomness used for signing.  If nil,
+	// crypto/rand.Reader is used.
+	Rand io.Reader
+	// Resolver finds the keys that verify the CWTs and JWTs consumed by
+	// FromMediaTypeWithOptions
+	Resolver KeyResolver
+}
+
+// ToMediaType serializes the receiver Eat according to the supplied EAT media
+// type.  Only the unprotected claims-set media types (UCCS and UJCS) can be
+// produced; use ToMediaTypeWithOptions for CWTs and JWTs.  If the media type
+// has an eat_profile parameter, it must match the eat_profile claim.
+//
+//nolint:gocritic
+func (e Eat) ToMediaType(mediaType string) ([]byte, error) {
+	return e.ToMediaTypeWithOptions(mediaType, MediaTypeOptions{})
+}
+
+// ToMediaTypeWithOptions is like ToMediaType, except that CWTs and JWTs are
+// also produced, by signing the receiver Eat with the signer in the supplied
+// options (see Sign and SignJWT).  Detached EAT Bundles cannot be produced.
+//
+//nolint:gocritic
+func (e Eat) ToMediaTypeWithOptions(mediaType string, opts MediaTypeOptions) ([]byte, error) {
+	mt, err := ParseMediaType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mt.CheckProfile(&e); err != nil {
+		return nil, err
+	}
+
+	switch mt.Type {
+	case MediaTypeUCCS:
+		return e.ToCBOR()
+	case MediaTypeUJCS:
+		return e.ToJSON()
+	case MediaTypeCWT, MediaTypeJWT:
+	default:
+		return nil, fmt.Errorf("serialization to %s is not supported", mt.Type)
+	}
+
+	if opts.Signer == nil {
+		return nil, fmt.Errorf("a signer is needed for serialization to %s", mt.Type)
+	}
+
+	r := opts.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+
+	if mt.Type == MediaTypeCWT {
+		return e.Sign(r, opts.Signer, opts.SignOptions...)
+	}
+
+	token, err := e.SignJWT(r, opts.Signer, opts.SignOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(token), nil
+}
+
+// FromMediaType deserializes the supplied data into the receiver Eat according
+// to the supplied EAT media type.  Only the unprotected claims-set media types
+// (UCCS, tagged or untagged, and UJCS) are accepted; use
+// FromMediaTypeWithOptions for CWTs and JWTs.  If the media type has an
+// eat_profile parameter, it must match the decoded eat_profile claim.
+func (e *Eat) FromMediaType(mediaType string, data []byte) error {
+	return e.FromMediaTypeWithOptions(mediaType, data, MediaTypeOptions{})
+}
+
+// FromMediaTypeWithOptions is like FromMediaType, except that CWTs and JWTs
+// are also accepted, and verified with the resolver in the supplied options
+// (see Verify and VerifyJWT).  Detached EAT Bundles are not accepted.
+func (e *Eat) FromMediaTypeWithOptions(mediaType string, data []byte, opts MediaTypeOptions) error {
+	mt, err := ParseMediaType(mediaType)
+	if err != nil {
+		return err
+	}
+
+	var decoded *Eat
+
+	switch mt.Type {
+	case MediaTypeUCCS, MediaTypeUJCS:
+		t, err := Parse(data)
+		if err != nil {
+			return err
+		}
+
+		if t.Format != mt.Format() {
+			return fmt.Errorf("expecting %s, found %s", mt.Format(), t.Format)
+		}
+
+		decoded = t.Eat
+	case MediaTypeCWT, MediaTypeJWT:
+		if opts.Resolver == nil {
+			return fmt.Errorf("a key resolver is needed for deserialization from %s", mt.Type)
+		}
+
+		var vt *VerifiedToken
+		if mt.Type == MediaTypeCWT {
+			vt, err = Verify(data, opts.Resolver)
+		} else {
+			vt, err = VerifyJWT(string(data), opts.Resolver)
+		}
+		if err != nil {
+			return err
+		}
+
+		decoded = vt.Eat
+	default:
+		return fmt.Errorf("deserialization from %s is not supported", mt.Type)
+	}
+
+	if err := mt.CheckProfile(decoded); err != nil {
+		return err
+	}
+
+	*e = *decoded
+
+	return nil
+}
+
+// errNotEATMediaType is returned by parseEATMediaType when the media type is
+// well-formed but is not an EAT media type
+var errNotEATMediaType = errors.New("not an EAT media type")
+
+// parseEATMediaType is like ParseMediaType, but returns errNotEATMediaType if
+// the supplied media type is not an EAT media type
+func parseEATMediaType(s string) (*MediaType, error) {
+	base, _, err := mime.ParseMediaType(s)
+	if err == nil {
+		if _, ok := eatFormats[base]; !ok {
+			return nil, errNotEATMediaType
+		}
+	}
+	return ParseMediaType(s)
+}
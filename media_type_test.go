@@ -0,0 +1,137 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMediaType_OK(t *testing.T) {
+	mt, err := ParseMediaType(`application/eat+cwt; eat_profile="tag:psacertified.org,2023:psa#tfm"`)
+	require.Nil(t, err)
+	assert.Equal(t, MediaTypeCWT, mt.Type)
+	assert.Equal(t, FormatCWT, mt.Format())
+
+	profile, err := mt.Profile.Get()
+	require.Nil(t, err)
+	assert.Equal(t, PSAProfileID, profile)
+
+	assert.Equal(t, `application/eat+cwt; eat_profile="tag:psacertified.org,2023:psa#tfm"`, mt.String())
+
+	_, err = mt.ContentFormat()
+	assert.EqualError(t, err, `no content-format registered for application/eat+cwt; eat_profile="tag:psacertified.org,2023:psa#tfm"`)
+
+	mt, err = ParseMediaType("Application/EAT-UCS+JSON")
+	require.Nil(t, err)
+	assert.Equal(t, MediaTypeUJCS, mt.String())
+
+	cf, err := mt.ContentFormat()
+	require.Nil(t, err)
+	assert.Equal(t, uint16(268), cf)
+
+	mt, err = MediaTypeFromContentFormat(cf)
+	require.Nil(t, err)
+	assert.Equal(t, MediaTypeUJCS, mt.Type)
+}
+
+func TestParseMediaType_NG(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		expected  string
+	}{
+		{"application/json", `"application/json" is not an EAT media type`},
+		{"application/eat+cwt; foo=bar", `unknown media type parameter "foo"`},
+		{"application/eat+cwt; eat_profile=abcd", "invalid eat_profile parameter: profile string must be an absolute URL or an ASN.1 OID"},
+		{"application/", `malformed media type "application/": mime: expected token after slash`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMediaType(test.mediaType)
+		assert.ErrorContains(t, err, test.expected, test.mediaType)
+	}
+
+	_, err := MediaTypeFromContentFormat(60)
+	assert.EqualError(t, err, "content-format 60 is not an EAT content-format")
+}
+
+func TestEat_MediaType_RoundTrip(t *testing.T) {
+	e := newTestClaims(t)
+
+	profile, err := NewProfile("http://arm.com/psa/2.0.0")
+	require.Nil(t, err)
+	e.Profile = profile
+
+	for _, mediaType := range []string{
+		MediaTypeUCCS,
+		MediaTypeUJCS,
+		MediaTypeUJCS + `; eat_profile="http://arm.com/psa/2.0.0"`,
+	} {
+		data, err := e.ToMediaType(mediaType)
+		require.Nil(t, err, mediaType)
+
+		var actual Eat
+		require.Nil(t, actual.FromMediaType(mediaType, data), mediaType)
+		assert.Equal(t, e, actual, mediaType)
+	}
+}
+
+func TestEat_MediaTypeWithOptions_RoundTrip(t *testing.T) {
+	e := newTestSignedClaims(t)
+
+	signer, resolver := newTestSignerResolver(t)
+	opts := MediaTypeOptions{Signer: signer, Resolver: resolver}
+
+	for _, mediaType := range []string{MediaTypeCWT, MediaTypeJWT, MediaTypeUCCS} {
+		data, err := e.ToMediaTypeWithOptions(mediaType, opts)
+		require.Nil(t, err, mediaType)
+
+		var actual Eat
+		require.Nil(t, actual.FromMediaTypeWithOptions(mediaType, data, opts), mediaType)
+		assert.Equal(t, e, actual, mediaType)
+	}
+
+	data, err := e.ToMediaTypeWithOptions(MediaTypeCWT, opts)
+	require.Nil(t, err)
+
+	_, otherResolver := newTestSignerResolver(t)
+	var actual Eat
+	err = actual.FromMediaTypeWithOptions(MediaTypeCWT, data, MediaTypeOptions{Resolver: otherResolver})
+	assert.ErrorContains(t, err, "verifying signature")
+}
+
+func TestEat_MediaType_NG(t *testing.T) {
+	e := newTestClaims(t)
+
+	_, err := e.ToMediaType(MediaTypeCWT)
+	assert.EqualError(t, err, "a signer is needed for serialization to application/eat+cwt")
+
+	_, err = e.ToMediaType(MediaTypeDEBCBOR)
+	assert.EqualError(t, err, "serialization to application/eat-bun+cbor is not supported")
+
+	_, err = e.ToMediaType(MediaTypeUCCS + `; eat_profile="urn:example"`)
+	assert.EqualError(t, err, `missing eat_profile claim, expecting "urn:example"`)
+
+	data, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	var actual Eat
+	assert.EqualError(t, actual.FromMediaType(MediaTypeUJCS, data), "expecting UJCS, found UCCS")
+	assert.EqualError(t, actual.FromMediaType(MediaTypeJWT, data),
+		"a key resolver is needed for deserialization from application/eat+jwt")
+	assert.EqualError(t, actual.FromMediaType(MediaTypeDEBJSON, data),
+		"deserialization from application/eat-bun+json is not supported")
+
+	profile, err := NewProfile("urn:example:other")
+	require.Nil(t, err)
+	e.Profile = profile
+
+	data, err = e.ToCBOR()
+	require.Nil(t, err)
+
+	assert.EqualError(t, actual.FromMediaType(MediaTypeUCCS+`; eat_profile="urn:example"`, data),
+		`eat_profile parameter "urn:example" does not match eat_profile claim "urn:example:other"`)
+}
@@ -0,0 +1,244 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	cose "github.com/veraison/go-cose"
+)
+
+// CoSigner is one of the signers of a COSE_Sign message
+type CoSigner struct {
+	// Signer makes the signature
+	Signer cose.Signer
+	// KeyID, if set, is carried in the unprotected header of the signature
+	KeyID []byte
+	// X5Chain, if set, is carried in the protected header of the signature
+	// (RFC 9360), starting with the certificate of the signing key
+	X5Chain [][]byte
+}
+
+// SignaturePolicy is the number of signatures of a COSE_Sign message that
+// must be valid for the token to verify.  SignaturePolicyAll requires all of
+// them, and any positive value n requires at least n (a quorum).
+type SignaturePolicy int
+
+// Common signature policies
+const (
+	SignaturePolicyAll SignaturePolicy = 0
+	SignaturePolicyAny SignaturePolicy = 1
+)
+
+// SignMulti serializes the receiver Eat and signs it with each of the supplied
+// co-signers, returning a COSE_Sign message.  The WithKeyID and WithX5Chain
+// options do not apply, use the fields of CoSigner instead.  A cnf claim, in
+// the Eat or in a nested submod, that would disclose a private or symmetric key
+// is refused.
+//
+//nolint:gocritic
+func (e Eat) SignMulti(rand io.Reader, signers []CoSigner, opts ...SignOption) ([]byte, error) {
+	var o signOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.kid != nil || len(o.x5chain) > 0 {
+		return nil, errors.New("key ID and x5chain must be set for each co-signer")
+	}
+
+	if len(signers) == 0 {
+		return nil, errors.New("no signers")
+	}
+
+	if err := e.validateCnf(); err != nil {
+		return nil, err
+	}
+
+	payload, err := e.ToCBOR()
+	if err != nil {
+		return nil, fmt.Errorf("encoding claims-set: %w", err)
+	}
+
+	msg := cose.NewSignMessage()
+	msg.Payload = payload
+
+	if len(o.headerClaims) > 0 {
+		claims, err := selectHeaderClaims(payload, o.headerClaims)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := msg.Headers.Protected.SetCWTClaims(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	coseSigners := make([]cose.Signer, 0, len(signers))
+
+	for i, s := range signers {
+		if s.Signer == nil {
+			return nil, fmt.Errorf("co-signer at index %d: no signer", i)
+		}
+
+		sig := cose.NewSignature()
+		sig.Headers.Protected.SetAlgorithm(s.Signer.Algorithm())
+
+		switch len(s.X5Chain) {
+		case 0:
+		case 1:
+			sig.Headers.Protected[cose.HeaderLabelX5Chain] = s.X5Chain[0]
+		default:
+			sig.Headers.Protected[cose.HeaderLabelX5Chain] = s.X5Chain
+		}
+
+		if s.KeyID != nil {
+			sig.Headers.Unprotected[cose.HeaderLabelKeyID] = s.KeyID
+		}
+
+		msg.Signatures = append(msg.Signatures, sig)
+		coseSigners = append(coseSigners, s.Signer)
+	}
+
+	if err := msg.Sign(rand, nil, coseSigners...); err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	data, err := msg.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.cwtTag {
+		return em.Marshal(cbor.RawTag{Number: cborTagCWT, Content: data})
+	}
+
+	return data, nil
+}
+
+// VerifyMulti verifies the supplied COSE_Sign message, optionally wrapped in a
+// CWT tag, and decodes its claims-set.  The key of each signature is found by
+// the supplied resolver from the headers of that signature, and the number of
+// distinct signers with a valid signature must satisfy the supplied policy.
+// Signatures whose resolved key has already been counted are ignored.  CWT
+// claims in the protected header are handled as in Verify.
+func VerifyMulti(data []byte, resolver KeyResolver, policy SignaturePolicy) (*VerifiedToken, error) {
+	if policy < 0 {
+		return nil, fmt.Errorf("invalid signature policy %d", policy)
+	}
+
+	if resolver == nil {
+		return nil, errors.New("no key resolver")
+	}
+
+	var msg cose.SignMessage
+	if err := msg.UnmarshalCBOR(skipCWTTag(data)); err != nil {
+		return nil, fmt.Errorf("decoding COSE_Sign: %w", err)
+	}
+
+	if msg.Payload == nil {
+		return nil, errors.New("detached COSE payload")
+	}
+
+	required := int(policy)
+	if policy == SignaturePolicyAll {
+		required = len(msg.Signatures)
+	} else if required > len(msg.Signatures) {
+		return nil, fmt.Errorf("policy requires %d signatures, found %d", required, len(msg.Signatures))
+	}
+
+	e, err := parseCBORClaims(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := msg.Headers.MarshalProtected()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		verified []cose.Headers
+		errs     []error
+		signers  = make(map[string]int)
+	)
+
+	for i, sig := range msg.Signatures {
+		id, err := verifySignature(sig, resolver, protected, msg.Payload, e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("signature at index %d: %w", i, err))
+			continue
+		}
+
+		// a signer counts once towards the policy, whatever the number of its
+		// signatures
+		if j, ok := signers[id]; ok {
+			errs = append(errs, fmt.Errorf("signature at index %d: same signer as index %d", i, j))
+			continue
+		}
+		signers[id] = i
+
+		verified = append(verified, sig.Headers)
+	}
+
+	if len(verified) < required {
+		return nil, fmt.Errorf(
+			"%d of %d signatures verified, %d required: %w",
+			len(verified), len(msg.Signatures), required, errors.Join(errs...),
+		)
+	}
+
+	t, err := newVerifiedToken(msg.Headers, msg.Payload, e)
+	if err != nil {
+		return nil, err
+	}
+
+	t.SignerHeaders = verified
+
+	return t, nil
+}
+
+// verifySignature verifies the supplied signature and returns the identity of
+// its signer, i.e. the DER encoding of the resolved public key
+func verifySignature(sig *cose.Signature, resolver KeyResolver, protected cbor.RawMessage, payload []byte, e *Eat) (string, error) {
+	verifier, key, err := resolveVerifierKey(resolver, sig.Headers, e)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sig.Verify(verifier, protected, payload, nil); err != nil {
+		return "", fmt.Errorf("verifying signature: %w", err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("encoding signer key: %w", err)
+	}
+
+	return string(der), nil
+}
+
+// skipCWTTag returns the content of the CWT tag wrapping the supplied data, or
+// the data itself if there is none
+func skipCWTTag(data []byte) []byte {
+	if !isCBORTag(data) {
+		return data
+	}
+
+	var tag cbor.RawTag
+	if err := dm.Unmarshal(data, &tag); err != nil || tag.Number != cborTagCWT {
+		return data
+	}
+
+	return tag.Content
+}
+
+func isCOSESign(data []byte) bool {
+	data = skipCWTTag(data)
+	return len(data) > 2 && data[0] == 0xd8 && data[1] == cborTagCOSESign
+}
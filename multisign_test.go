@@ -0,0 +1,146 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+// newTestCoSigners returns a platform RoT (ES256) and a TEE (EdDSA) co-signer,
+// and a resolver that knows their keys by kid
+func newTestCoSigners(t *testing.T) ([]CoSigner, map[string]crypto.PublicKey) {
+	rot := newTestP256Key(t)
+
+	rotSigner, err := cose.NewSigner(cose.AlgorithmES256, rot)
+	require.Nil(t, err)
+
+	teePub, tee, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+
+	teeSigner, err := cose.NewSigner(cose.AlgorithmEdDSA, tee)
+	require.Nil(t, err)
+
+	signers := []CoSigner{
+		{Signer: rotSigner, KeyID: []byte("rot")},
+		{Signer: teeSigner, KeyID: []byte("tee")},
+	}
+
+	keys := map[string]crypto.PublicKey{
+		"rot": rot.Public(),
+		"tee": teePub,
+	}
+
+	return signers, keys
+}
+
+func newTestKIDResolver(keys map[string]crypto.PublicKey) KeyResolver {
+	return KeyResolverFunc(func(q KeyQuery) (crypto.PublicKey, error) {
+		k, ok := keys[string(q.KeyID)]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", q.KeyID)
+		}
+		return k, nil
+	})
+}
+
+func TestSignMulti_VerifyMulti(t *testing.T) {
+	signers, keys := newTestCoSigners(t)
+	e := newTestSignedClaims(t)
+
+	signed, err := e.SignMulti(rand.Reader, signers, WithCWTTag(), WithHeaderClaims(cose.CWTClaimIssuer))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xd8, 0x3d, 0xd8, 0x62}, signed[:4])
+
+	resolver := newTestKIDResolver(keys)
+
+	for _, policy := range []SignaturePolicy{SignaturePolicyAll, SignaturePolicyAny, 2} {
+		actual, err := VerifyMulti(signed, resolver, policy)
+		require.Nil(t, err)
+		assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+		assert.Equal(t, e.Issuer, actual.HeaderClaims.Issuer)
+		assert.Len(t, actual.SignerHeaders, 2)
+	}
+
+	actual, err := Verify(signed, resolver)
+	require.Nil(t, err)
+	assert.Len(t, actual.SignerHeaders, 2)
+
+	var s Submods
+	assert.Nil(t, s.Add("co-signed", signed))
+}
+
+func TestVerifyMulti_policy(t *testing.T) {
+	signers, keys := newTestCoSigners(t)
+
+	signed, err := newTestSignedClaims(t).SignMulti(rand.Reader, signers)
+	require.Nil(t, err)
+
+	delete(keys, "tee")
+	resolver := newTestKIDResolver(keys)
+
+	actual, err := VerifyMulti(signed, resolver, SignaturePolicyAny)
+	require.Nil(t, err)
+	require.Len(t, actual.SignerHeaders, 1)
+	assert.Equal(t, []byte("rot"), actual.SignerHeaders[0].Unprotected[cose.HeaderLabelKeyID])
+
+	_, err = VerifyMulti(signed, resolver, SignaturePolicyAll)
+	assert.EqualError(t, err, `1 of 2 signatures verified, 2 required: signature at index 1: resolving key: unknown kid "tee"`)
+
+	_, err = VerifyMulti(signed, resolver, 2)
+	assert.EqualError(t, err, `1 of 2 signatures verified, 2 required: signature at index 1: resolving key: unknown kid "tee"`)
+
+	_, err = VerifyMulti(signed, resolver, 3)
+	assert.EqualError(t, err, "policy requires 3 signatures, found 2")
+
+	_, err = VerifyMulti(signed, resolver, -1)
+	assert.EqualError(t, err, "invalid signature policy -1")
+}
+
+func TestVerifyMulti_duplicate_signer(t *testing.T) {
+	signers, keys := newTestCoSigners(t)
+
+	signed, err := newTestSignedClaims(t).SignMulti(rand.Reader, signers[:1])
+	require.Nil(t, err)
+
+	var msg cose.SignMessage
+	require.Nil(t, msg.UnmarshalCBOR(signed))
+	msg.Signatures = append(msg.Signatures, msg.Signatures[0])
+
+	duplicated, err := msg.MarshalCBOR()
+	require.Nil(t, err)
+
+	resolver := newTestKIDResolver(keys)
+
+	_, err = VerifyMulti(duplicated, resolver, 2)
+	assert.EqualError(t, err, "1 of 2 signatures verified, 2 required: signature at index 1: same signer as index 0")
+
+	_, err = VerifyMulti(duplicated, resolver, SignaturePolicyAll)
+	assert.EqualError(t, err, "1 of 2 signatures verified, 2 required: signature at index 1: same signer as index 0")
+
+	actual, err := VerifyMulti(duplicated, resolver, SignaturePolicyAny)
+	require.Nil(t, err)
+	assert.Len(t, actual.SignerHeaders, 1)
+}
+
+func TestSignMulti_NG(t *testing.T) {
+	signers, _ := newTestCoSigners(t)
+	e := newTestSignedClaims(t)
+
+	_, err := e.SignMulti(rand.Reader, nil)
+	assert.EqualError(t, err, "no signers")
+
+	_, err = e.SignMulti(rand.Reader, signers, WithKeyID([]byte("kid")))
+	assert.EqualError(t, err, "key ID and x5chain must be set for each co-signer")
+
+	_, err = e.SignMulti(rand.Reader, []CoSigner{{KeyID: []byte("kid")}})
+	assert.EqualError(t, err, "co-signer at index 0: no signer")
+}
@@ -4,7 +4,6 @@
 package eat
 
 import (
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -71,9 +70,9 @@ func (n *nonce) UnmarshalCBOR(data []byte) error {
 	return nil
 }
 
-// MarshalJSON encodes the receiver (non-array) nonce as a JSON string
+// MarshalJSON encodes the receiver (non-array) nonce as a base64url JSON string
 func (n nonce) MarshalJSON() ([]byte, error) {
-	return json.Marshal(n.value)
+	return json.Marshal(encodeBinaryData(n.value))
 }
 
 // UnmarshalJSON decodes the supplied JSON data to a (non-array) nonce
@@ -86,7 +85,7 @@ func (n *nonce) UnmarshalJSON(data []byte) error {
 
 	switch t := v.(type) {
 	case string:
-		value, err := base64.StdEncoding.DecodeString(t)
+		value, err := decodeBinaryData(t)
 		if err != nil {
 			return err
 		}
@@ -192,8 +191,8 @@ func (ns *Nonce) UnmarshalCBOR(data []byte) error {
 }
 
 // MarshalJSON encodes the receiver Nonce as either a JSON string containing
-// the base64 encoding of the binary nonce (if the array comprises only one
-// element) or as an array of base64-encoded JSON strings.
+// the base64url encoding of the binary nonce (if the array comprises only one
+// element) or as an array of base64url-encoded JSON strings.
 func (ns Nonce) MarshalJSON() ([]byte, error) {
 	if err := ns.Validate(); err != nil {
 		return nil, fmt.Errorf("JSON encoding failed: %w", err)
@@ -206,7 +205,8 @@ func (ns Nonce) MarshalJSON() ([]byte, error) {
 	return json.Marshal([]nonce(ns))
 }
 
-// UnmarshalJSON decodes a EAT nonce in JSON format.
+// UnmarshalJSON decodes a EAT nonce in JSON format.  Both base64url and
+// standard base64 encodings are accepted.
 func (ns *Nonce) UnmarshalJSON(data []byte) error {
 	if isJSONArray(data) {
 		return json.Unmarshal(data, (*[]nonce)(ns))
@@ -258,7 +258,7 @@ func TestNonce_MarshalJSON_single_ok(t *testing.T) {
 	})
 	require.Nil(t, err)
 
-	expected := []byte(`"3q2+796tvu8="`)
+	expected := []byte(`"3q2-796tvu8"`)
 
 	actual, err := nonces.MarshalJSON()
 
@@ -278,8 +278,8 @@ func TestNonce_MarshalJSON_multiple_ok(t *testing.T) {
 	}
 
 	expected := `[
-		"AAAAAAAAAAA=",
-		"AQEBAQEBAQE="
+		"AAAAAAAAAAA",
+		"AQEBAQEBAQE"
 	]`
 
 	actual, err := nonces.MarshalJSON()
@@ -393,3 +393,20 @@ func TestNonce_UnmarshalJSON_two_entries_ok(t *testing.T) {
 		assert.Equal(t, expected[i], actual.GetI(i))
 	}
 }
+
+func TestNonce_UnmarshalJSON_base64url_ok(t *testing.T) {
+	expected := []byte{
+		0xfb, 0xff, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef,
+	}
+
+	for _, tv := range []string{
+		`"-_--796tvu8"`,  // base64url
+		`"-_--796tvu8="`, // base64url, padded
+		`"+/++796tvu8="`, // standard base64
+		`"+/++796tvu8"`,  // standard base64, unpadded
+	} {
+		actual := Nonce{}
+		require.Nil(t, actual.UnmarshalJSON([]byte(tv)), tv)
+		assert.Equal(t, expected, actual.GetI(0), tv)
+	}
+}
@@ -0,0 +1,597 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	cbor "github.com/fxamacker/cbor/v2"
+)
+
+const (
+	cborTagCOSEEncrypt0 = 16
+	cborTagCOSEMac0     = 17
+	cborTagCOSEEncrypt  = 96
+	cborTagCOSEMac      = 97
+	cborTagCOSESign     = 98
+	cborTagUCCS         = 601
+	cborTagDEB          = 602
+
+	// CBOR tags derived from CoAP content-formats (RFC 9277):
+	// TN(CF) = cmwTagBase + CF
+	cmwTagBase = 1668546817
+	cmwTagMax  = cmwTagBase + 65024
+)
+
+// Format identifies the encapsulation of a token recognized by Parse
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	// FormatCWT is a CBOR Web Token (COSE_Sign1, COSE_Sign, COSE_Mac0 or
+	// COSE_Mac, optionally wrapped in a CWT tag)
+	FormatCWT
+	// FormatJWT is a JSON Web Token in compact serialization
+	FormatJWT
+	// FormatUCCS is a bare CBOR claims-set, optionally wrapped in a UCCS tag
+	FormatUCCS
+	// FormatUJCS is a bare JSON claims-set
+	FormatUJCS
+	// FormatDEB is a Detached EAT Bundle, either CBOR or JSON
+	FormatDEB
+	// FormatCMW is a Conceptual Message Wrapper record, either CBOR, JSON or
+	// CBOR tag
+	FormatCMW
+	// FormatCMWCollection is a CMW collection, either CBOR or JSON
+	FormatCMWCollection
+)
+
+var formatNames = map[Format]string{
+	FormatCWT:           "CWT",
+	FormatJWT:           "JWT",
+	FormatUCCS:          "UCCS",
+	FormatUJCS:          "UJCS",
+	FormatDEB:           "DEB",
+	FormatCMW:           "CMW",
+	FormatCMWCollection: "CMW collection",
+}
+
+// String returns the name of the receiver Format
+func (f Format) String() string {
+	if name, ok := formatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParsedToken is the result of Parse
+type ParsedToken struct {
+	// Format is the detected encapsulation
+	Format Format
+	// MediaType is the media type of the token.  For a CMW record, it is the
+	// type of the wrapped message, which may be empty if the record uses an
+	// unknown content-format.
+	MediaType string
+	// Eat is the decoded claims-set.  For a DEB, it is that of the main token;
+	// for a CMW record, that of the wrapped token, if it is an EAT.
+	Eat *Eat
+	// Detached holds the detached claims-sets of a DEB, by submod name
+	Detached map[string]Eat
+	// Wrapped is the token wrapped in a CMW record, or nil if the record does
+	// not contain an EAT
+	Wrapped *ParsedToken
+	// Collection holds the entries of a CMW collection, by label
+	Collection map[string]*ParsedToken
+}
+
+// Parse detects the format of the supplied token from its CBOR tags or JSON
+// shape, and decodes it.  The following formats are recognized: CWT, JWT,
+// UCCS, bare CBOR and JSON claims-sets, Detached EAT Bundles and Conceptual
+// Message Wrappers (records and collections).  Encrypted tokens are not
+// supported.
+//
+// Signatures and MACs are NOT verified: the claims of protected tokens are
+// returned as found in the payload.
+func Parse(data []byte) (*ParsedToken, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty token")
+	}
+
+	switch {
+	case isCBORTag(data):
+		return parseCBORTag(data)
+	case isCBORMap(data):
+		return parseCBORMap(data)
+	case isCBORArray(data):
+		return parseCBORArray(data)
+	}
+
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case len(data) == 0:
+		return nil, errors.New("empty token")
+	case data[0] == '{':
+		return parseJSONObject(data)
+	case isJSONArray(data):
+		return parseJSONArray(data)
+	case isJWT(data):
+		return parseJWT(string(data))
+	default:
+		return nil, errors.New("unrecognized token format")
+	}
+}
+
+func parseCBORTag(data []byte) (*ParsedToken, error) {
+	var tag cbor.RawTag
+	if err := dm.Unmarshal(data, &tag); err != nil {
+		return nil, fmt.Errorf("malformed CBOR tag: %w", err)
+	}
+
+	switch n := tag.Number; {
+	case n == cborTagCWT:
+		if len(tag.Content) == 0 || !isCBORTag(tag.Content) {
+			return nil, errors.New("CWT tag does not wrap a tagged COSE message")
+		}
+		return parseCOSE(tag.Content)
+	case isCOSETag(n):
+		return parseCOSE(data)
+	case n == cborTagUCCS:
+		e, err := parseCBORClaims(tag.Content)
+		if err != nil {
+			return nil, err
+		}
+		return &ParsedToken{Format: FormatUCCS, MediaType: MediaTypeUCCS, Eat: e}, nil
+	case n == cborTagDEB:
+		return parseCBORDEB(tag.Content)
+	case n >= cmwTagBase && n <= cmwTagMax:
+		return parseCBORCMW(data)
+	default:
+		return nil, fmt.Errorf("unsupported CBOR tag %d", n)
+	}
+}
+
+func isCOSETag(n uint64) bool {
+	switch n {
+	case cborTagCOSESign1, cborTagCOSESign, cborTagCOSEMac0, cborTagCOSEMac,
+		cborTagCOSEEncrypt0, cborTagCOSEEncrypt:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseCOSE extracts and decodes the claims-set carried in the payload of the
+// supplied COSE message, which may be tagged or untagged
+func parseCOSE(data []byte) (*ParsedToken, error) {
+	content := data
+
+	if isCBORTag(data) {
+		var tag cbor.RawTag
+		if err := dm.Unmarshal(data, &tag); err != nil {
+			return nil, fmt.Errorf("malformed COSE message: %w", err)
+		}
+
+		switch tag.Number {
+		case cborTagCOSEEncrypt0, cborTagCOSEEncrypt:
+			return nil, errors.New("encrypted tokens are not supported")
+		case cborTagCOSESign1, cborTagCOSESign, cborTagCOSEMac0, cborTagCOSEMac:
+		default:
+			return nil, fmt.Errorf("unsupported COSE tag %d", tag.Number)
+		}
+
+		content = tag.Content
+	}
+
+	// in all signed and MACed COSE structures, the payload is the third item
+	var msg []cbor.RawMessage
+	if err := dm.Unmarshal(content, &msg); err != nil {
+		return nil, fmt.Errorf("malformed COSE message: %w", err)
+	}
+
+	if len(msg) < 4 {
+		return nil, fmt.Errorf("malformed COSE message: expecting at least 4 items, found %d", len(msg))
+	}
+
+	var payload []byte
+	if err := dm.Unmarshal(msg[2], &payload); err != nil {
+		return nil, fmt.Errorf("malformed COSE payload: %w", err)
+	}
+
+	if payload == nil {
+		return nil, errors.New("detached COSE payload")
+	}
+
+	e, err := parseCBORClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedToken{Format: FormatCWT, MediaType: MediaTypeCWT, Eat: e}, nil
+}
+
+func parseCBORClaims(data []byte) (*Eat, error) {
+	var e Eat
+	if err := e.FromCBOR(data); err != nil {
+		return nil, fmt.Errorf("decoding CBOR claims-set: %w", err)
+	}
+	return &e, nil
+}
+
+func parseJSONClaims(data []byte) (*Eat, error) {
+	var e Eat
+	if err := e.FromJSON(data); err != nil {
+		return nil, fmt.Errorf("decoding JSON claims-set: %w", err)
+	}
+	return &e, nil
+}
+
+// parseCBORMap decodes either a CMW collection or a bare claims-set.  A map is
+// treated as a CMW collection if all its entries are CMWs.
+func parseCBORMap(data []byte) (*ParsedToken, error) {
+	var c CMW
+	if err := c.UnmarshalCBOR(data); err == nil {
+		return c.parse(MediaTypeCMWCBOR)
+	}
+
+	e, err := parseCBORClaims(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedToken{Format: FormatUCCS, MediaType: MediaTypeUCCS, Eat: e}, nil
+}
+
+func parseCBORCMW(data []byte) (*ParsedToken, error) {
+	var c CMW
+	if err := c.UnmarshalCBOR(data); err != nil {
+		return nil, err
+	}
+	return c.parse(MediaTypeCMWCBOR)
+}
+
+// isCBORCMWRecord checks that the supplied array has the shape of a CMW record:
+// [ type: uint / tstr, value: bstr, ? ind: uint ]
+func isCBORCMWRecord(rec []cbor.RawMessage) bool {
+	_, err := decodeCBORCMWRecord(rec)
+	return err == nil
+}
+
+func isCBORUint(data []byte) bool {
+	return len(data) > 0 && (data[0]&0xe0) == 0x00
+}
+
+// parseCMWValue decodes the value of a CMW record if its media type is an EAT
+// media type, and checks that the detected format and profile match
+func parseCMWValue(mediaType string, value []byte) (*ParsedToken, error) {
+	t := ParsedToken{Format: FormatCMW, MediaType: mediaType}
+
+	if mediaType == "" {
+		return &t, nil
+	}
+
+	mt, err := parseEATMediaType(mediaType)
+	if errors.Is(err, errNotEATMediaType) {
+		return &t, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("CMW type: %w", err)
+	}
+
+	wrapped, err := Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("CMW value of type %s: %w", mt.Type, err)
+	}
+
+	if wrapped.Format != mt.Format() {
+		return nil, fmt.Errorf("CMW value of type %s: found %s", mt.Type, wrapped.Format)
+	}
+
+	if err := mt.CheckProfile(wrapped.Eat); err != nil {
+		return nil, fmt.Errorf("CMW value of type %s: %w", mt.Type, err)
+	}
+
+	t.Wrapped = wrapped
+	t.Eat = wrapped.Eat
+
+	return &t, nil
+}
+
+// eatFormats maps the EAT media types onto the corresponding format
+var eatFormats = map[string]Format{
+	MediaTypeCWT:     FormatCWT,
+	MediaTypeJWT:     FormatJWT,
+	MediaTypeUCCS:    FormatUCCS,
+	MediaTypeUJCS:    FormatUJCS,
+	MediaTypeDEBCBOR: FormatDEB,
+	MediaTypeDEBJSON: FormatDEB,
+}
+
+// parseCBORArray decodes either a CMW record, a DEB or an untagged COSE message
+func parseCBORArray(data []byte) (*ParsedToken, error) {
+	var a []cbor.RawMessage
+	if err := dm.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("malformed CBOR array: %w", err)
+	}
+
+	switch {
+	case isCBORCMWRecord(a):
+		return parseCBORCMW(data)
+	case len(a) == 2:
+		return parseCBORDEB(data)
+	case len(a) >= 4 && isCBORByteString(a[0]) && isCBORMap(a[1]):
+		return parseCOSE(data)
+	default:
+		return nil, errors.New("unrecognized CBOR array")
+	}
+}
+
+/*
+Detached-EAT-Bundle = [
+	main-token : Nested-Token,
+	detached-claims-sets: {
+		+ tstr => cbor-wrapped-claims-set / json-wrapped-claims-set
+	}
+]
+*/
+
+func parseCBORDEB(data []byte) (*ParsedToken, error) {
+	var deb struct {
+		_        struct{} `cbor:",toarray"`
+		Main     cbor.RawMessage
+		Detached map[string]cbor.RawMessage
+	}
+
+	if err := dm.Unmarshal(data, &deb); err != nil {
+		return nil, fmt.Errorf("malformed DEB: %w", err)
+	}
+
+	if len(deb.Main) == 0 {
+		return nil, errors.New("malformed DEB: missing main token")
+	}
+
+	var (
+		main *ParsedToken
+		err  error
+	)
+
+	switch {
+	case isCBORByteString(deb.Main):
+		var token []byte
+		if err = dm.Unmarshal(deb.Main, &token); err == nil {
+			main, err = parseDEBMainToken(token, FormatCWT)
+		}
+	case isCBORTextString(deb.Main):
+		var token string
+		if err = dm.Unmarshal(deb.Main, &token); err == nil {
+			main, err = parseDEBMainToken([]byte(token), FormatJWT)
+		}
+	default:
+		err = errors.New("main token must be a byte or text string")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("DEB: %w", err)
+	}
+
+	t := ParsedToken{
+		Format:    FormatDEB,
+		MediaType: MediaTypeDEBCBOR,
+		Eat:       main.Eat,
+		Detached:  make(map[string]Eat, len(deb.Detached)),
+	}
+
+	for name, v := range deb.Detached {
+		var wrapped []byte
+
+		switch {
+		case len(v) > 0 && isCBORByteString(v):
+			err = dm.Unmarshal(v, &wrapped)
+		case len(v) > 0 && isCBORTextString(v):
+			var s string
+			if err = dm.Unmarshal(v, &s); err == nil {
+				wrapped, err = decodeBinaryData(s)
+			}
+		default:
+			err = errors.New("must be a byte or text string")
+		}
+
+		if err == nil {
+			err = t.addDetached(name, wrapped)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("DEB: detached claims-set %q: %w", name, err)
+		}
+	}
+
+	return &t, nil
+}
+
+func parseDEBMainToken(token []byte, expected Format) (*ParsedToken, error) {
+	main, err := Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("main token: %w", err)
+	}
+
+	if main.Format != expected {
+		return nil, fmt.Errorf("main token: expecting %s, found %s", expected, main.Format)
+	}
+
+	return main, nil
+}
+
+// addDetached decodes the supplied wrapped claims-set, which may be either
+// CBOR or JSON, and adds it to the detached claims-sets of the receiver
+func (t *ParsedToken) addDetached(name string, wrapped []byte) error {
+	var (
+		e   *Eat
+		err error
+	)
+
+	if len(wrapped) > 0 && wrapped[0] == '{' {
+		e, err = parseJSONClaims(wrapped)
+	} else {
+		e, err = parseCBORClaims(wrapped)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	t.Detached[name] = *e
+
+	return nil
+}
+
+// parseJSONObject decodes either a CMW collection or a bare claims-set.  An
+// object is treated as a CMW collection if all its members are CMWs.
+func parseJSONObject(data []byte) (*ParsedToken, error) {
+	var c CMW
+	if err := c.UnmarshalJSON(data); err == nil {
+		return c.parse(MediaTypeCMWJSON)
+	}
+
+	e, err := parseJSONClaims(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedToken{Format: FormatUJCS, MediaType: MediaTypeUJCS, Eat: e}, nil
+}
+
+func parseJSONCMW(data []byte) (*ParsedToken, error) {
+	var c CMW
+	if err := c.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return c.parse(MediaTypeCMWJSON)
+}
+
+// parseJSONArray decodes either a CMW record or a JSON DEB
+func parseJSONArray(data []byte) (*ParsedToken, error) {
+	var a []json.RawMessage
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("malformed JSON array: %w", err)
+	}
+
+	if len(a) > 0 && isJSONArray(bytes.TrimSpace(a[0])) {
+		return parseJSONDEB(a)
+	}
+
+	return parseJSONCMW(data)
+}
+
+/*
+JSON-Selector = [
+	type: "JWT" / "CBOR" / "BUNDLE" / "DIGEST",
+	nested-token: JWT-Message / B64URL-Tagged-CBOR / BUNDLE-Messages / Detached-Submodule-Digest
+]
+*/
+
+func parseJSONDEB(a []json.RawMessage) (*ParsedToken, error) {
+	if len(a) != 2 {
+		return nil, fmt.Errorf("malformed DEB: expecting 2 items, found %d", len(a))
+	}
+
+	var selector []json.RawMessage
+	if err := json.Unmarshal(a[0], &selector); err != nil || len(selector) != 2 {
+		return nil, errors.New("malformed DEB: main token must be a JSON selector")
+	}
+
+	var typ, token string
+	if err := json.Unmarshal(selector[0], &typ); err != nil {
+		return nil, errors.New("malformed DEB: invalid JSON selector type")
+	}
+	if err := json.Unmarshal(selector[1], &token); err != nil {
+		return nil, errors.New("malformed DEB: invalid JSON selector token")
+	}
+
+	var (
+		main *ParsedToken
+		err  error
+	)
+
+	switch typ {
+	case "JWT":
+		main, err = parseDEBMainToken([]byte(token), FormatJWT)
+	case "CBOR":
+		var cwt []byte
+		if cwt, err = decodeBinaryData(token); err == nil {
+			main, err = parseDEBMainToken(cwt, FormatCWT)
+		}
+	default:
+		err = fmt.Errorf("unsupported main token type %q", typ)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("DEB: %w", err)
+	}
+
+	var detached map[string]binaryData
+	if err := json.Unmarshal(a[1], &detached); err != nil {
+		return nil, fmt.Errorf("malformed DEB: detached claims-sets: %w", err)
+	}
+
+	t := ParsedToken{
+		Format:    FormatDEB,
+		MediaType: MediaTypeDEBJSON,
+		Eat:       main.Eat,
+		Detached:  make(map[string]Eat, len(detached)),
+	}
+
+	for name, wrapped := range detached {
+		if err := t.addDetached(name, wrapped); err != nil {
+			return nil, fmt.Errorf("DEB: detached claims-set %q: %w", name, err)
+		}
+	}
+
+	return &t, nil
+}
+
+// jwtSyntax matches the compact serialization of a JWS (three parts) or a JWE
+// (five parts)
+var jwtSyntax = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*(\.[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*)?$`)
+
+func isJWT(data []byte) bool {
+	return jwtSyntax.Match(data)
+}
+
+func parseJWT(token string) (*ParsedToken, error) {
+	parts := strings.Split(token, ".")
+
+	switch len(parts) {
+	case 3:
+	case 5:
+		return nil, errors.New("encrypted tokens are not supported")
+	default:
+		return nil, errors.New("malformed JWT")
+	}
+
+	header, err := decodeBinaryData(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	var h map[string]interface{}
+	if err := json.Unmarshal(header, &h); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	payload, err := decodeBinaryData(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT payload: %w", err)
+	}
+
+	e, err := parseJSONClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedToken{Format: FormatJWT, MediaType: MediaTypeJWT, Eat: e}, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"testing"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func newTestClaims(t *testing.T) Eat {
+	nonce := Nonce{}
+	require.Nil(t, nonce.Add(nonceBytes))
+
+	return Eat{Nonce: &nonce, UEID: &ueID}
+}
+
+func newTestJWT(t *testing.T, e Eat) string {
+	payload, err := e.ToJSON()
+	require.Nil(t, err)
+
+	return encodeBinaryData([]byte(`{"alg":"none"}`)) + "." + encodeBinaryData(payload) + "."
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := em.Marshal(v)
+	require.Nil(t, err)
+	return data
+}
+
+func TestParse_Formats(t *testing.T) {
+	e := newTestClaims(t)
+
+	claimsCBOR, err := e.ToCBOR()
+	require.Nil(t, err)
+	claimsJSON, err := e.ToJSON()
+	require.Nil(t, err)
+
+	cwt := signTestToken(t, e, cose.AlgorithmES256, nil, true)
+	sign1 := signTestToken(t, e, cose.AlgorithmES256, nil, false)
+	jwt := newTestJWT(t, e)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		format    Format
+		mediaType string
+	}{
+		{"CWT", cwt, FormatCWT, MediaTypeCWT},
+		{"COSE_Sign1", sign1, FormatCWT, MediaTypeCWT},
+		{"untagged COSE_Sign1", sign1[1:], FormatCWT, MediaTypeCWT},
+		{"JWT", []byte(jwt), FormatJWT, MediaTypeJWT},
+		{"UCCS", mustMarshal(t, cbor.Tag{Number: cborTagUCCS, Content: cbor.RawMessage(claimsCBOR)}), FormatUCCS, MediaTypeUCCS},
+		{"CBOR claims-set", claimsCBOR, FormatUCCS, MediaTypeUCCS},
+		{"JSON claims-set", claimsJSON, FormatUJCS, MediaTypeUJCS},
+		{"CMW CBOR record", mustMarshal(t, []interface{}{263, cwt}), FormatCMW, MediaTypeCWT},
+		{"CMW CBOR tag", mustMarshal(t, cbor.Tag{Number: cmwTagBase + 267, Content: claimsCBOR}), FormatCMW, MediaTypeUCCS},
+		{"CMW JSON record", []byte(`["application/eat+jwt", "` + encodeBinaryData([]byte(jwt)) + `"]`), FormatCMW, MediaTypeJWT},
+	}
+
+	for _, test := range tests {
+		actual, err := Parse(test.data)
+		require.Nil(t, err, test.name)
+		assert.Equal(t, test.format, actual.Format, test.name)
+		assert.Equal(t, test.mediaType, actual.MediaType, test.name)
+		require.NotNil(t, actual.Eat, test.name)
+		assert.Equal(t, e.Nonce, actual.Eat.Nonce, test.name)
+		assert.Equal(t, e.UEID, actual.Eat.UEID, test.name)
+	}
+}
+
+func TestParse_DEB(t *testing.T) {
+	e := newTestClaims(t)
+
+	detached := newTestClaims(t)
+	detachedCBOR, err := detached.ToCBOR()
+	require.Nil(t, err)
+	detachedJSON, err := detached.ToJSON()
+	require.Nil(t, err)
+
+	cwt := signTestToken(t, e, cose.AlgorithmES256, nil, true)
+
+	deb := mustMarshal(t, cbor.Tag{
+		Number: cborTagDEB,
+		Content: []interface{}{
+			cwt,
+			map[string]interface{}{
+				"cbor": detachedCBOR,
+				"json": encodeBinaryData(detachedJSON),
+			},
+		},
+	})
+
+	actual, err := Parse(deb)
+	require.Nil(t, err)
+	assert.Equal(t, FormatDEB, actual.Format)
+	assert.Equal(t, MediaTypeDEBCBOR, actual.MediaType)
+	assert.Equal(t, e.Nonce, actual.Eat.Nonce)
+	require.Len(t, actual.Detached, 2)
+	assert.Equal(t, detached.UEID, actual.Detached["cbor"].UEID)
+	assert.Equal(t, detached.UEID, actual.Detached["json"].UEID)
+
+	jsonDEB := `[["JWT", "` + newTestJWT(t, e) + `"], {"json": "` + encodeBinaryData(detachedJSON) + `"}]`
+
+	actual, err = Parse([]byte(jsonDEB))
+	require.Nil(t, err)
+	assert.Equal(t, FormatDEB, actual.Format)
+	assert.Equal(t, MediaTypeDEBJSON, actual.MediaType)
+	assert.Equal(t, e.Nonce, actual.Eat.Nonce)
+	assert.Equal(t, detached.UEID, actual.Detached["json"].UEID)
+
+	_, err = Parse([]byte(`[["DIGEST", "x"], {}]`))
+	assert.EqualError(t, err, `DEB: unsupported main token type "DIGEST"`)
+}
+
+func TestParse_CMWCollection(t *testing.T) {
+	e := newTestClaims(t)
+	jwt := newTestJWT(t, e)
+
+	data := `{
+		"__cmwc_t": "tag:example.com,2025:composite-attester",
+		"eat": ["application/eat+jwt", "` + encodeBinaryData([]byte(jwt)) + `"],
+		"other": ["application/vnd.example.evidence", "AAEC", 4]
+	}`
+
+	actual, err := Parse([]byte(data))
+	require.Nil(t, err)
+	assert.Equal(t, FormatCMWCollection, actual.Format)
+	assert.Equal(t, MediaTypeCMWJSON, actual.MediaType)
+	require.Len(t, actual.Collection, 2)
+	assert.Equal(t, e.Nonce, actual.Collection["eat"].Eat.Nonce)
+	assert.Equal(t, FormatJWT, actual.Collection["eat"].Wrapped.Format)
+	assert.Equal(t, "application/vnd.example.evidence", actual.Collection["other"].MediaType)
+	assert.Nil(t, actual.Collection["other"].Eat)
+
+	// CBOR collection without the type entry
+	cwt := signTestToken(t, e, cose.AlgorithmES256, nil, true)
+	cborData := mustMarshal(t, map[string]interface{}{
+		"eat":   []interface{}{263, cwt},
+		"other": []interface{}{uint(30001), []byte{0x00, 0x01, 0x02}},
+	})
+
+	actual, err = Parse(cborData)
+	require.Nil(t, err)
+	assert.Equal(t, FormatCMWCollection, actual.Format)
+	assert.Equal(t, MediaTypeCMWCBOR, actual.MediaType)
+	assert.Equal(t, e.UEID, actual.Collection["eat"].Eat.UEID)
+	assert.Equal(t, "", actual.Collection["other"].MediaType)
+}
+
+func TestParse_NG(t *testing.T) {
+	e := newTestClaims(t)
+	claimsCBOR, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"empty", nil, "empty token"},
+		{"blank", []byte(" \n"), "empty token"},
+		{"garbage", []byte("hello world"), "unrecognized token format"},
+		{"unknown tag", []byte{0xd8, 0x20, 0x60}, "unsupported CBOR tag 32"},
+		{"COSE_Encrypt0", mustMarshal(t, cbor.Tag{Number: cborTagCOSEEncrypt0, Content: []interface{}{}}), "encrypted tokens are not supported"},
+		{"JWE", []byte("a.b.c.d.e"), "encrypted tokens are not supported"},
+		{
+			"CMW type mismatch",
+			mustMarshal(t, []interface{}{MediaTypeCWT, claimsCBOR}),
+			"CMW value of type application/eat+cwt: found UCCS",
+		},
+		{
+			"CMW profile mismatch",
+			mustMarshal(t, []interface{}{MediaTypeUCCS + `; eat_profile="urn:example"`, claimsCBOR}),
+			`CMW value of type application/eat-ucs+cbor: missing eat_profile claim, expecting "urn:example"`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.data)
+		assert.EqualError(t, err, test.expected, test.name)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// ProofOfPossession is a signature over a verifier-issued challenge, made with
+// the key confirmed by the cnf claim of an Eat
+type ProofOfPossession struct {
+	// Challenge is the fresh challenge issued by the verifier
+	Challenge []byte
+	// Algorithm is the COSE algorithm of Signature
+	Algorithm cose.Algorithm
+	// Signature is the signature over Challenge
+	Signature []byte
+	// Key is the public key of the signer.  It can be omitted if the cnf claim
+	// carries the key itself, or if the key can be resolved.
+	Key crypto.PublicKey
+	// KeyID is the identifier of Key, matched against a cnf kid
+	KeyID []byte
+}
+
+// VerifyPoP checks that the supplied proof of possession was made with the key
+// confirmed by the cnf claim of the receiver Eat.  The signer key must be the
+// confirmation key, or have the confirmed thumbprint (either a COSE Key
+// Thumbprint or a JWK Thumbprint, using SHA-256).  A confirmed kid is resolved
+// with the supplied resolver, and the signer key, if any, must be the resolved
+// key.  If the proof does not carry the signer key for a thumbprint, it is
+// looked up with the supplied resolver.  The resolver may be nil if the cnf
+// claim carries the key itself.  Encrypted confirmation keys are not supported.
+//
+//nolint:gocritic
+func (e Eat) VerifyPoP(pop ProofOfPossession, resolver KeyResolver) error {
+	if e.Cnf == nil {
+		return errors.New("no cnf claim")
+	}
+
+	q := KeyQuery{
+		KeyID:     pop.KeyID,
+		Algorithm: pop.Algorithm,
+		Issuer:    e.Issuer,
+		UEID:      e.UEID,
+		OemID:     e.OemID,
+	}
+
+	key, err := e.Cnf.confirm(pop.Key, q, resolver)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := cose.NewVerifier(pop.Algorithm, key)
+	if err != nil {
+		return fmt.Errorf("proof of possession: %w", err)
+	}
+
+	if err := verifier.Verify(pop.Challenge, pop.Signature); err != nil {
+		return fmt.Errorf("proof of possession: %w", err)
+	}
+
+	return nil
+}
+
+// confirm returns the public key to verify a proof of possession with, after
+// checking that the supplied key, if any, is the one confirmed by the receiver
+// KeyConfirmation.  Keys are resolved with the supplied query and resolver.
+//
+//nolint:gocritic
+func (c KeyConfirmation) confirm(pub crypto.PublicKey, q KeyQuery, resolver KeyResolver) (crypto.PublicKey, error) {
+	switch {
+	case c.Key != nil:
+		cnf, err := c.Key.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("cnf key: %w", err)
+		}
+
+		if pub != nil && !publicKeyEqual(cnf, pub) {
+			return nil, errors.New("signer key does not match cnf key")
+		}
+
+		return cnf, nil
+	case c.KeyThumbprint != nil:
+		if pub == nil {
+			if resolver == nil {
+				return nil, errors.New("signer key needed to match cnf thumbprint")
+			}
+
+			k, err := resolver.ResolveKey(q)
+			if err != nil {
+				return nil, fmt.Errorf("resolving signer key: %w", err)
+			}
+
+			pub = k
+		}
+
+		k, err := NewCOSEKeyFromPublic(pub)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, thumbprint := range []func(crypto.Hash) ([]byte, error){k.Thumbprint, k.JWKThumbprint} {
+			tp, err := thumbprint(crypto.SHA256)
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(tp, *c.KeyThumbprint) {
+				return pub, nil
+			}
+		}
+
+		return nil, errors.New("signer key does not match cnf thumbprint")
+	case c.Kid != nil:
+		if q.KeyID != nil && !bytes.Equal(q.KeyID, *c.Kid) {
+			return nil, errors.New("signer kid does not match cnf kid")
+		}
+
+		// the key must come from the resolver: a key supplied by the prover
+		// only proves that the prover knows the kid
+		if resolver == nil {
+			return nil, errors.New("key resolver needed to resolve cnf kid")
+		}
+
+		q.KeyID = *c.Kid
+
+		cnf, err := resolver.ResolveKey(q)
+		if err != nil {
+			return nil, fmt.Errorf("resolving cnf kid: %w", err)
+		}
+
+		if pub != nil && !publicKeyEqual(cnf, pub) {
+			return nil, errors.New("signer key does not match cnf kid key")
+		}
+
+		return cnf, nil
+	case c.EncryptedKey != nil:
+		return nil, errors.New("encrypted cnf key is not supported")
+	default:
+		return nil, errors.New("empty cnf claim")
+	}
+}
+
+func publicKeyEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func newTestPoP(t *testing.T, priv *ecdsa.PrivateKey) ProofOfPossession {
+	signer, err := cose.NewSigner(cose.AlgorithmES256, priv)
+	require.Nil(t, err)
+
+	challenge := []byte("verifier challenge")
+
+	sig, err := signer.Sign(rand.Reader, challenge)
+	require.Nil(t, err)
+
+	return ProofOfPossession{
+		Challenge: challenge,
+		Algorithm: cose.AlgorithmES256,
+		Signature: sig,
+	}
+}
+
+func TestEat_VerifyPoP(t *testing.T) {
+	priv := newTestP256Key(t)
+
+	key, err := NewCOSEKeyFromPublic(priv.Public())
+	require.Nil(t, err)
+	ckt, err := key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	jkt, err := key.JWKThumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	kid := []byte("pop-key")
+
+	pop := newTestPoP(t, priv)
+
+	// cnf key, no signer key needed
+	e := Eat{CWTClaims: CWTClaims{Cnf: &KeyConfirmation{Key: key}}}
+	assert.Nil(t, e.VerifyPoP(pop, nil))
+
+	withKey := pop
+	withKey.Key = priv.Public()
+	withKey.KeyID = kid
+
+	for _, cnf := range []KeyConfirmation{
+		{Key: key},
+		{KeyThumbprint: &ckt},
+		{KeyThumbprint: &jkt},
+	} {
+		e.Cnf = &cnf
+		assert.Nil(t, e.VerifyPoP(withKey, nil))
+	}
+
+	// a cnf kid is only trusted through the resolver
+	e.Cnf = &KeyConfirmation{Kid: &kid}
+	assert.EqualError(t, e.VerifyPoP(withKey, nil), "key resolver needed to resolve cnf kid")
+
+	resolver := KeyResolverFunc(func(q KeyQuery) (crypto.PublicKey, error) {
+		assert.Equal(t, kid, q.KeyID)
+		return priv.Public(), nil
+	})
+	assert.Nil(t, e.VerifyPoP(withKey, resolver))
+	assert.Nil(t, e.VerifyPoP(pop, resolver))
+
+	// thumbprint with the signer key resolved from the proof kid
+	e.Cnf = &KeyConfirmation{KeyThumbprint: &ckt}
+	withKID := pop
+	withKID.KeyID = kid
+	assert.Nil(t, e.VerifyPoP(withKID, resolver))
+}
+
+func TestEat_VerifyPoP_NG(t *testing.T) {
+	priv := newTestP256Key(t)
+	other := newTestP256Key(t)
+
+	key, err := NewCOSEKeyFromPublic(priv.Public())
+	require.Nil(t, err)
+	ckt, err := key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	kid := []byte("pop-key")
+
+	pop := newTestPoP(t, priv)
+
+	assert.EqualError(t, Eat{}.VerifyPoP(pop, nil), "no cnf claim")
+
+	e := Eat{CWTClaims: CWTClaims{Cnf: &KeyConfirmation{KeyThumbprint: &ckt}}}
+	assert.EqualError(t, e.VerifyPoP(pop, nil), "signer key needed to match cnf thumbprint")
+
+	wrongKey := pop
+	wrongKey.Key = other.Public()
+	assert.EqualError(t, e.VerifyPoP(wrongKey, nil), "signer key does not match cnf thumbprint")
+
+	e.Cnf = &KeyConfirmation{Key: key}
+	assert.EqualError(t, e.VerifyPoP(wrongKey, nil), "signer key does not match cnf key")
+
+	resolver := StaticKeyResolver(priv.Public())
+
+	e.Cnf = &KeyConfirmation{Kid: &kid}
+	wrongKey.KeyID = []byte("other-key")
+	assert.EqualError(t, e.VerifyPoP(wrongKey, resolver), "signer kid does not match cnf kid")
+
+	// right kid, but a key of the prover's own choosing
+	wrongKey.KeyID = kid
+	assert.EqualError(t, e.VerifyPoP(wrongKey, resolver), "signer key does not match cnf kid key")
+
+	// right kid, but the signature was not made with the resolved key
+	otherSig := newTestPoP(t, other)
+	otherSig.KeyID = kid
+	assert.ErrorContains(t, e.VerifyPoP(otherSig, resolver), "proof of possession: verification error")
+
+	e.Cnf = &KeyConfirmation{Key: key}
+	badSig := pop
+	badSig.Challenge = []byte("stale challenge")
+	assert.ErrorContains(t, e.VerifyPoP(badSig, nil), "proof of possession")
+}
@@ -0,0 +1,321 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Encoding identifies the serialization format of an EAT
+type Encoding uint
+
+const (
+	// EncodingCBOR is the CBOR serialization
+	EncodingCBOR Encoding = 1 << iota
+	// EncodingJSON is the JSON serialization
+	EncodingJSON
+)
+
+// String returns the name of the receiver Encoding
+func (e Encoding) String() string {
+	switch e {
+	case EncodingCBOR:
+		return "CBOR"
+	case EncodingJSON:
+		return "JSON"
+	default:
+		return fmt.Sprintf("Encoding(%d)", uint(e))
+	}
+}
+
+// ProfileRules declares the constraints that a profile places on the claims of
+// an EAT.  Claims are identified by their RFC 9711 JSON names (e.g.,
+// "eat_nonce", "ueid", "iat").
+type ProfileRules struct {
+	// RequiredClaims must be present
+	RequiredClaims []string
+	// OptionalClaims may be present.  They are only relevant if StrictClaims
+	// is set.
+	OptionalClaims []string
+	// ForbiddenClaims must not be present
+	ForbiddenClaims []string
+	// StrictClaims rejects any claim that is not listed in either
+	// RequiredClaims or OptionalClaims (eat_profile is always allowed)
+	StrictClaims bool
+	// MinNonceSize and MaxNonceSize, if not zero, restrict the size of each
+	// nonce value
+	MinNonceSize int
+	MaxNonceSize int
+	// Encodings, if not zero, is the set of allowed encodings
+	Encodings Encoding
+}
+
+// ProfilePlugin is implemented by EAT profiles that can be registered with
+// RegisterProfile.  Registered profiles are automatically selected, based on
+// the value of the eat_profile claim, by Eat.Validate and on decoding.
+type ProfilePlugin interface {
+	// ID returns the profile identifier, either an absolute URI or an OID in
+	// dotted-decimal notation
+	ID() string
+	// Rules returns the claim rules of the profile
+	Rules() ProfileRules
+	// Validate applies any profile-specific checks that cannot be expressed
+	// using ProfileRules.  It is called after the rules have been checked.
+	Validate(e *Eat) error
+}
+
+// BasicProfile is a ProfilePlugin made of an identifier, a set of rules and an
+// optional list of custom validators
+type BasicProfile struct {
+	ProfileID  string
+	ClaimRules ProfileRules
+	Validators []func(*Eat) error
+}
+
+// ID returns the profile identifier
+func (p BasicProfile) ID() string {
+	return p.ProfileID
+}
+
+// Rules returns the claim rules of the profile
+func (p BasicProfile) Rules() ProfileRules {
+	return p.ClaimRules
+}
+
+// Validate runs the custom validators of the profile and returns all the
+// errors they report
+func (p BasicProfile) Validate(e *Eat) error {
+	var errs []error
+
+	for _, v := range p.Validators {
+		if err := v(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]ProfilePlugin{}
+)
+
+// RegisterProfile adds the supplied profile to the registry.  An error is
+// returned if the profile identifier is malformed, if the rules refer to
+// unknown claims, or if a profile with the same identifier is already
+// registered.
+func RegisterProfile(p ProfilePlugin) error {
+	key, err := profileKey(p.ID())
+	if err != nil {
+		return err
+	}
+
+	r := p.Rules()
+	for _, set := range [][]string{r.RequiredClaims, r.OptionalClaims, r.ForbiddenClaims} {
+		for _, name := range set {
+			if !isKnownClaim(name) {
+				return fmt.Errorf("profile %s: unknown claim %q", key, name)
+			}
+		}
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	if _, ok := profiles[key]; ok {
+		return fmt.Errorf("profile %s already registered", key)
+	}
+
+	profiles[key] = p
+
+	return nil
+}
+
+// UnregisterProfile removes the profile with the supplied identifier from the
+// registry, if present
+func UnregisterProfile(id string) {
+	key, err := profileKey(id)
+	if err != nil {
+		return
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	delete(profiles, key)
+}
+
+// LookupProfile returns the registered profile that matches the supplied
+// Profile, if any
+func LookupProfile(p Profile) (ProfilePlugin, bool) {
+	key, err := p.Get()
+	if err != nil {
+		return nil, false
+	}
+
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+
+	plugin, ok := profiles[key]
+
+	return plugin, ok
+}
+
+// profileKey normalizes the supplied profile identifier so that it can be
+// compared to the value of a decoded eat_profile claim
+func profileKey(id string) (string, error) {
+	p, err := NewProfile(id)
+	if err != nil {
+		return "", err
+	}
+	return p.Get()
+}
+
+// claimNames returns the JSON names of the claims present in the receiver Eat
+//
+//nolint:gocritic
+func (e Eat) claimNames() []string {
+	var names []string
+	collectClaimNames(reflect.ValueOf(e), &names)
+	return names
+}
+
+func collectClaimNames(v reflect.Value, names *[]string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.Anonymous {
+			collectClaimNames(v.Field(i), names)
+			continue
+		}
+
+		name := jsonClaimName(f)
+		if name == "" || v.Field(i).IsNil() {
+			continue
+		}
+
+		*names = append(*names, name)
+	}
+}
+
+func jsonClaimName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+var knownClaims = func() map[string]bool {
+	m := map[string]bool{}
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous {
+				collect(f.Type)
+				continue
+			}
+			if name := jsonClaimName(f); name != "" {
+				m[name] = true
+			}
+		}
+	}
+	collect(reflect.TypeOf(Eat{}))
+	return m
+}()
+
+func isKnownClaim(name string) bool {
+	return knownClaims[name]
+}
+
+// checkProfileRules returns all the violations of the supplied rules by the
+// receiver Eat.  The encoding is only checked if enc is not zero.
+func (e *Eat) checkProfileRules(r ProfileRules, enc Encoding) []error {
+	var errs []error
+
+	present := map[string]bool{}
+	for _, name := range e.claimNames() {
+		present[name] = true
+	}
+
+	allowed := map[string]bool{"eat_profile": true}
+
+	for _, name := range r.RequiredClaims {
+		allowed[name] = true
+		if !present[name] {
+			errs = append(errs, fmt.Errorf("missing required claim %s", name))
+		}
+	}
+
+	for _, name := range r.OptionalClaims {
+		allowed[name] = true
+	}
+
+	for _, name := range r.ForbiddenClaims {
+		if present[name] {
+			errs = append(errs, fmt.Errorf("forbidden claim %s is present", name))
+		}
+	}
+
+	if r.StrictClaims {
+		for _, name := range e.claimNames() {
+			if !allowed[name] {
+				errs = append(errs, fmt.Errorf("claim %s is not allowed by the profile", name))
+			}
+		}
+	}
+
+	if e.Nonce != nil {
+		for i, n := range *e.Nonce {
+			size := len(n.get())
+			if (r.MinNonceSize != 0 && size < r.MinNonceSize) ||
+				(r.MaxNonceSize != 0 && size > r.MaxNonceSize) {
+				errs = append(errs, fmt.Errorf(
+					"nonce at index %d has size %d, outside the range allowed by the profile [%d, %d]",
+					i, size, r.MinNonceSize, r.MaxNonceSize,
+				))
+			}
+		}
+	}
+
+	if enc != 0 && r.Encodings != 0 && r.Encodings&enc == 0 {
+		errs = append(errs, fmt.Errorf("%s encoding is not allowed by the profile", enc))
+	}
+
+	return errs
+}
+
+// validateProfile checks the receiver Eat against the registered profile
+// identified by its eat_profile claim, if any
+func (e *Eat) validateProfile(enc Encoding) error {
+	if e.Profile == nil {
+		return nil
+	}
+
+	plugin, ok := LookupProfile(*e.Profile)
+	if !ok {
+		return nil
+	}
+
+	errs := e.checkProfileRules(plugin.Rules(), enc)
+
+	if err := plugin.Validate(e); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("profile %s: %w", plugin.ID(), errors.Join(errs...))
+}
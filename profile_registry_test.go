@@ -0,0 +1,132 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProfileID = "http://example.com/eat/test-profile"
+
+func registerTestProfile(t *testing.T, p ProfilePlugin) {
+	require.Nil(t, RegisterProfile(p))
+	t.Cleanup(func() { UnregisterProfile(p.ID()) })
+}
+
+func TestRegisterProfile_NG(t *testing.T) {
+	assert.EqualError(t,
+		RegisterProfile(BasicProfile{ProfileID: testProfileID, ClaimRules: ProfileRules{
+			RequiredClaims: []string{"eat-nonce"},
+		}}),
+		`profile http://example.com/eat/test-profile: unknown claim "eat-nonce"`,
+	)
+
+	assert.NotNil(t, RegisterProfile(BasicProfile{ProfileID: "not a profile"}))
+
+	registerTestProfile(t, BasicProfile{ProfileID: testProfileID})
+	assert.EqualError(t,
+		RegisterProfile(BasicProfile{ProfileID: testProfileID}),
+		"profile http://example.com/eat/test-profile already registered",
+	)
+}
+
+func TestEat_Validate_Profile(t *testing.T) {
+	registerTestProfile(t, BasicProfile{
+		ProfileID: testProfileID,
+		ClaimRules: ProfileRules{
+			RequiredClaims:  []string{"eat_nonce", "ueid"},
+			OptionalClaims:  []string{"iat"},
+			ForbiddenClaims: []string{"location"},
+			StrictClaims:    true,
+			MaxNonceSize:    8,
+		},
+		Validators: []func(*Eat) error{
+			func(e *Eat) error {
+				if e.IssuedAt != nil && e.Uptime == nil {
+					return errors.New("iat requires uptime")
+				}
+				return nil
+			},
+		},
+	})
+
+	tv := newTestProfileEat(t, testProfileID)
+	assert.Nil(t, tv.Validate())
+
+	tv.UEID = nil
+	tv.Location = &location
+	tv.Issuer = &issuer
+	tv.IssuedAt = &epoch
+	require.Nil(t, tv.Nonce.Add(make([]byte, 16)))
+
+	assert.EqualError(t, tv.Validate(),
+		"profile "+testProfileID+": missing required claim ueid\n"+
+			"forbidden claim location is present\n"+
+			"claim location is not allowed by the profile\n"+
+			"claim iss is not allowed by the profile\n"+
+			"nonce at index 1 has size 16, outside the range allowed by the profile [0, 8]\n"+
+			"iat requires uptime",
+	)
+}
+
+func TestEat_Validate_UnregisteredProfile(t *testing.T) {
+	tv := newTestProfileEat(t, testProfileID)
+	tv.Location = &location
+	assert.Nil(t, tv.Validate())
+}
+
+func TestEat_Validate_Claims(t *testing.T) {
+	badUEID := UEID{0xff}
+	badDebug := Debug(42)
+
+	var submods Submods
+	require.Nil(t, submods.Add("inner", Eat{UEID: &badUEID}))
+
+	tv := Eat{DebugStatus: &badDebug, Submods: &submods}
+
+	assert.EqualError(t, tv.Validate(),
+		"dbgstat: out of range value 42 for Debug type\n"+
+			"submod inner: ueid: invalid UEID type 255",
+	)
+}
+
+func TestEat_Decode_Profile(t *testing.T) {
+	registerTestProfile(t, BasicProfile{
+		ProfileID: testProfileID,
+		ClaimRules: ProfileRules{
+			RequiredClaims: []string{"eat_nonce"},
+			Encodings:      EncodingCBOR,
+		},
+	})
+
+	tv := newTestProfileEat(t, testProfileID)
+
+	data, err := tv.ToCBOR()
+	require.Nil(t, err)
+
+	var actual Eat
+	assert.Nil(t, actual.FromCBOR(data))
+	assert.Equal(t, tv, actual)
+
+	data, err = tv.ToJSON()
+	require.Nil(t, err)
+
+	actual = Eat{}
+	assert.EqualError(t, actual.FromJSON(data),
+		"profile "+testProfileID+": JSON encoding is not allowed by the profile",
+	)
+
+	tv.Nonce = nil
+	data, err = tv.ToCBOR()
+	require.Nil(t, err)
+
+	actual = Eat{}
+	assert.EqualError(t, actual.FromCBOR(data),
+		"profile "+testProfileID+": missing required claim eat_nonce",
+	)
+}
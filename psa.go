@@ -0,0 +1,434 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+/*
+psa-token = {
+	psa-nonce
+	psa-instance-id
+	psa-verification-service-indicator-type
+	psa-profile
+	psa-implementation-id
+	psa-client-id
+	psa-lifecycle
+	psa-certification-reference-type
+	? psa-boot-seed
+	psa-software-components
+}
+
+psa-software-component = {
+	? &(measurement-type: 1) => text
+	&(measurement-value: 2) => psa-hash-type
+	? &(version: 4) => text
+	&(signer-id: 5) => psa-hash-type
+	? &(measurement-desc: 6) => text
+}
+
+psa-hash-type = bytes .size 32 / bytes .size 48 / bytes .size 64
+*/
+
+// PSAProfileID identifies the PSA Attestation Token profile defined in RFC 9783
+const PSAProfileID = "tag:psacertified.org,2023:psa#tfm"
+
+// PSADefaultComponentName is the name of the measured component converted
+// from a PSA software component without a measurement type
+const PSADefaultComponentName = "psa-sw-component"
+
+// PSAImplementationIDSize is the size of the PSA implementation ID in bytes
+const PSAImplementationIDSize = 32
+
+// PSALifecycle is the PSA security lifecycle.  The most significant byte
+// encodes the lifecycle state, the least significant byte is implementation
+// defined.
+type PSALifecycle uint16
+
+// PSA security lifecycle states
+const (
+	PSALifecycleUnknown                PSALifecycle = 0x0000
+	PSALifecycleAssemblyAndTest        PSALifecycle = 0x1000
+	PSALifecyclePSARoTProvisioning     PSALifecycle = 0x2000
+	PSALifecycleSecured                PSALifecycle = 0x3000
+	PSALifecycleNonPSARoTDebug         PSALifecycle = 0x4000
+	PSALifecycleRecoverablePSARoTDebug PSALifecycle = 0x5000
+	PSALifecycleDecommissioned         PSALifecycle = 0x6000
+)
+
+var psaLifecycleNames = map[PSALifecycle]string{
+	PSALifecycleUnknown:                "unknown",
+	PSALifecycleAssemblyAndTest:        "assembly-and-test",
+	PSALifecyclePSARoTProvisioning:     "psa-rot-provisioning",
+	PSALifecycleSecured:                "secured",
+	PSALifecycleNonPSARoTDebug:         "non-psa-rot-debug",
+	PSALifecycleRecoverablePSARoTDebug: "recoverable-psa-rot-debug",
+	PSALifecycleDecommissioned:         "decommissioned",
+}
+
+// State returns the lifecycle state of the receiver PSALifecycle, i.e., its
+// value with the implementation defined byte cleared
+func (l PSALifecycle) State() PSALifecycle {
+	return l & 0xff00
+}
+
+// Validate checks that the receiver PSALifecycle encodes a known state
+func (l PSALifecycle) Validate() error {
+	if _, ok := psaLifecycleNames[l.State()]; !ok {
+		return fmt.Errorf("unknown security lifecycle state %#04x", uint16(l.State()))
+	}
+	return nil
+}
+
+// String returns the name of the lifecycle state followed by the
+// implementation defined byte, e.g., "secured-0x01"
+func (l PSALifecycle) String() string {
+	name, ok := psaLifecycleNames[l.State()]
+	if !ok {
+		return fmt.Sprintf("invalid-%#04x", uint16(l))
+	}
+	return fmt.Sprintf("%s-%#02x", name, uint16(l&0xff))
+}
+
+// PSASwComponent models the psa-software-component type defined in RFC 9783
+type PSASwComponent struct {
+	MeasurementType  *string `cbor:"1,keyasint,omitempty"`
+	MeasurementValue []byte  `cbor:"2,keyasint"`
+	Version          *string `cbor:"4,keyasint,omitempty"`
+	SignerID         []byte  `cbor:"5,keyasint"`
+	// MeasurementDesc is the name of the hash algorithm used to compute the
+	// measurement value, e.g., "sha-256"
+	MeasurementDesc *string `cbor:"6,keyasint,omitempty"`
+}
+
+func validatePSAHash(v []byte) error {
+	switch len(v) {
+	case 32, 48, 64:
+		return nil
+	default:
+		return fmt.Errorf("size must be 32, 48 or 64 bytes; found %d", len(v))
+	}
+}
+
+// Validate checks that the measurement value and signer ID of the receiver
+// PSASwComponent have a valid size
+func (c PSASwComponent) Validate() error {
+	if err := validatePSAHash(c.MeasurementValue); err != nil {
+		return fmt.Errorf("measurement value: %w", err)
+	}
+	if err := validatePSAHash(c.SignerID); err != nil {
+		return fmt.Errorf("signer ID: %w", err)
+	}
+	return nil
+}
+
+// ToMeasuredComponent converts the receiver PSASwComponent into a
+// MeasuredComponent.  The measurement type, or PSADefaultComponentName if
+// absent, becomes the component name, and the digest algorithm is taken from
+// the measurement description or, if absent, inferred from the size of the
+// measurement value.
+func (c PSASwComponent) ToMeasuredComponent() (*MeasuredComponent, error) {
+	name := PSADefaultComponentName
+	if c.MeasurementType != nil && *c.MeasurementType != "" {
+		name = *c.MeasurementType
+	}
+
+	var (
+		alg uint64
+		err error
+	)
+
+	if c.MeasurementDesc != nil {
+		alg, err = hashAlgorithmFromName(*c.MeasurementDesc)
+	} else {
+		alg, err = psaHashAlgorithmFromSize(len(c.MeasurementValue))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mc := MeasuredComponent{
+		Id:          ComponentID{Name: name},
+		Measurement: &Digest{Alg: alg, Value: c.MeasurementValue},
+		Signers:     &[][]byte{c.SignerID},
+	}
+
+	if c.Version != nil {
+		mc.Id.Version = &Version{Version: *c.Version}
+	}
+
+	return &mc, nil
+}
+
+// FromMeasuredComponent sets the receiver PSASwComponent from the supplied
+// MeasuredComponent, which must carry a digested measurement and exactly one
+// signer
+func (c *PSASwComponent) FromMeasuredComponent(mc MeasuredComponent) error {
+	if mc.Measurement == nil {
+		return errors.New("no digested measurement in measured component")
+	}
+
+	if mc.Signers == nil || len(*mc.Signers) != 1 {
+		return errors.New("exactly one signer is required")
+	}
+
+	name, err := hashAlgorithmName(mc.Measurement.Alg)
+	if err != nil {
+		return err
+	}
+
+	*c = PSASwComponent{
+		MeasurementValue: mc.Measurement.Value,
+		SignerID:         (*mc.Signers)[0],
+		MeasurementDesc:  &name,
+	}
+
+	if mc.Id.Name != PSADefaultComponentName {
+		mtype := mc.Id.Name
+		c.MeasurementType = &mtype
+	}
+
+	if mc.Id.Version != nil {
+		version := mc.Id.Version.Version
+		c.Version = &version
+	}
+
+	return nil
+}
+
+func psaHashAlgorithmFromSize(size int) (uint64, error) {
+	switch size {
+	case 32:
+		return hashAlgorithmNames["sha-256"], nil
+	case 48:
+		return hashAlgorithmNames["sha-384"], nil
+	case 64:
+		return hashAlgorithmNames["sha-512"], nil
+	default:
+		return 0, fmt.Errorf("cannot infer hash algorithm from measurement size %d", size)
+	}
+}
+
+func hashAlgorithmName(algID uint64) (string, error) {
+	for name, id := range hashAlgorithmNames {
+		if id == algID {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown hash algorithm %d", algID)
+}
+
+// PSAToken is a typed view of the claims-set of a PSA Attestation Token (RFC
+// 9783).  The claims that PSA shares with EAT use the corresponding types of
+// this package.
+type PSAToken struct {
+	Nonce                  *Nonce            `cbor:"10,keyasint,omitempty"`
+	InstanceID             *UEID             `cbor:"256,keyasint,omitempty"`
+	Profile                *Profile          `cbor:"265,keyasint,omitempty"`
+	BootSeed               *[]byte           `cbor:"268,keyasint,omitempty"`
+	ClientID               *int              `cbor:"2394,keyasint,omitempty"`
+	SecurityLifecycle      *PSALifecycle     `cbor:"2395,keyasint,omitempty"`
+	ImplementationID       *[]byte           `cbor:"2396,keyasint,omitempty"`
+	CertificationReference *string           `cbor:"2398,keyasint,omitempty"`
+	SoftwareComponents     *[]PSASwComponent `cbor:"2399,keyasint,omitempty"`
+	VerificationService    *string           `cbor:"2400,keyasint,omitempty"`
+}
+
+// FromCBOR deserializes the supplied CBOR encoded claims-set into the receiver
+// PSAToken
+func (t *PSAToken) FromCBOR(data []byte) error {
+	return dm.Unmarshal(data, t)
+}
+
+// ToCBOR serializes the receiver PSAToken into a CBOR encoded claims-set
+//
+//nolint:gocritic
+func (t PSAToken) ToCBOR() ([]byte, error) {
+	return em.Marshal(t)
+}
+
+// psaCertificationReference matches an EAN-13, optionally followed by a
+// 5-digit certificate number separated by "-" or "_"
+var psaCertificationReference = regexp.MustCompile(`^[0-9]{13}([-_][0-9]{5})?$`)
+
+// Validate checks that the receiver PSAToken carries all the claims mandated
+// by RFC 9783, and that they are well-formed.  All the errors found are
+// returned.
+//
+//nolint:gocritic
+func (t PSAToken) Validate() error {
+	var errs []error
+
+	check := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	missing := errors.New("missing mandatory claim")
+
+	if t.Profile == nil {
+		check("eat_profile", missing)
+	} else if id, _ := t.Profile.Get(); id != PSAProfileID {
+		check("eat_profile", fmt.Errorf("expecting %q, found %q", PSAProfileID, id))
+	}
+
+	if t.Nonce == nil {
+		check("eat_nonce", missing)
+	} else {
+		check("eat_nonce", validatePSANonce(*t.Nonce))
+	}
+
+	if t.InstanceID == nil {
+		check("ueid", missing)
+	} else {
+		check("ueid", validatePSAInstanceID(*t.InstanceID))
+	}
+
+	if t.ClientID == nil {
+		check("psa-client-id", missing)
+	}
+
+	if t.SecurityLifecycle == nil {
+		check("psa-security-lifecycle", missing)
+	} else {
+		check("psa-security-lifecycle", t.SecurityLifecycle.Validate())
+	}
+
+	if t.ImplementationID == nil {
+		check("psa-implementation-id", missing)
+	} else if len(*t.ImplementationID) != PSAImplementationIDSize {
+		check("psa-implementation-id", fmt.Errorf(
+			"size must be %d bytes; found %d", PSAImplementationIDSize, len(*t.ImplementationID),
+		))
+	}
+
+	if t.CertificationReference != nil && !psaCertificationReference.MatchString(*t.CertificationReference) {
+		check("psa-certification-reference", fmt.Errorf("%q is not an EAN-13 or EAN-13+5", *t.CertificationReference))
+	}
+
+	if t.SoftwareComponents == nil || len(*t.SoftwareComponents) == 0 {
+		check("psa-software-components", missing)
+	} else {
+		for i, c := range *t.SoftwareComponents {
+			check(fmt.Sprintf("psa-software-components[%d]", i), c.Validate())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ToEat returns an Eat populated with the claims that the receiver PSAToken
+// shares with EAT (eat_profile, eat_nonce, ueid and bootseed).  PSA-specific
+// claims have no EAT equivalent and are not carried over; use
+// MeasuredComponents to convert the software components.
+//
+//nolint:gocritic
+func (t PSAToken) ToEat() Eat {
+	return Eat{
+		Profile:  t.Profile,
+		Nonce:    t.Nonce,
+		UEID:     t.InstanceID,
+		BootSeed: t.BootSeed,
+	}
+}
+
+// FromEat sets the claims that the receiver PSAToken shares with EAT from the
+// supplied Eat.  PSA-specific claims are left untouched.
+//
+//nolint:gocritic
+func (t *PSAToken) FromEat(e Eat) {
+	t.Profile = e.Profile
+	t.Nonce = e.Nonce
+	t.InstanceID = e.UEID
+	t.BootSeed = e.BootSeed
+}
+
+// MeasuredComponents converts the software components of the receiver
+// PSAToken into measured components
+//
+//nolint:gocritic
+func (t PSAToken) MeasuredComponents() ([]MeasuredComponent, error) {
+	if t.SoftwareComponents == nil {
+		return nil, nil
+	}
+
+	mcs := make([]MeasuredComponent, 0, len(*t.SoftwareComponents))
+
+	for i, c := range *t.SoftwareComponents {
+		mc, err := c.ToMeasuredComponent()
+		if err != nil {
+			return nil, fmt.Errorf("software component at index %d: %w", i, err)
+		}
+		mcs = append(mcs, *mc)
+	}
+
+	return mcs, nil
+}
+
+// SetMeasuredComponents replaces the software components of the receiver
+// PSAToken with the supplied measured components
+func (t *PSAToken) SetMeasuredComponents(mcs []MeasuredComponent) error {
+	cs := make([]PSASwComponent, len(mcs))
+
+	for i, mc := range mcs {
+		if err := cs[i].FromMeasuredComponent(mc); err != nil {
+			return fmt.Errorf("measured component at index %d: %w", i, err)
+		}
+	}
+
+	t.SoftwareComponents = &cs
+
+	return nil
+}
+
+func validatePSANonce(n Nonce) error {
+	if n.Len() != 1 {
+		return fmt.Errorf("exactly one nonce is required, found %d", n.Len())
+	}
+	return validatePSAHash(n.GetI(0))
+}
+
+func validatePSAInstanceID(u UEID) error {
+	if len(u) != 33 || u[0] != UEIDTypeRAND {
+		return errors.New("instance ID must be a 33 bytes RAND UEID")
+	}
+	return nil
+}
+
+func init() {
+	err := RegisterProfile(BasicProfile{
+		ProfileID: PSAProfileID,
+		ClaimRules: ProfileRules{
+			RequiredClaims: []string{"eat_nonce", "ueid"},
+			Encodings:      EncodingCBOR,
+		},
+		Validators: []func(*Eat) error{
+			func(e *Eat) error {
+				if e.Nonce == nil {
+					return nil
+				}
+				if err := validatePSANonce(*e.Nonce); err != nil {
+					return fmt.Errorf("eat_nonce: %w", err)
+				}
+				return nil
+			},
+			func(e *Eat) error {
+				if e.UEID == nil {
+					return nil
+				}
+				if err := validatePSAInstanceID(*e.UEID); err != nil {
+					return fmt.Errorf("ueid: %w", err)
+				}
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/swid"
+)
+
+func newTestPSAToken(t *testing.T) PSAToken {
+	profile, err := NewProfile(PSAProfileID)
+	require.Nil(t, err)
+
+	nonce := Nonce{}
+	require.Nil(t, nonce.Add(bytes.Repeat([]byte{0x01}, 32)))
+
+	instanceID := UEID(append([]byte{UEIDTypeRAND}, bytes.Repeat([]byte{0x02}, 32)...))
+	implID := bytes.Repeat([]byte{0x03}, 32)
+	clientID := 1
+	lifecycle := PSALifecycleSecured
+	certRef := "1234567890123-12345"
+	vsi := "https://veraison.example/v1/challenge-response"
+	mtype := "BL"
+	version := "2.1.0"
+	desc := "sha-256"
+
+	return PSAToken{
+		Nonce:                  &nonce,
+		InstanceID:             &instanceID,
+		Profile:                profile,
+		ClientID:               &clientID,
+		SecurityLifecycle:      &lifecycle,
+		ImplementationID:       &implID,
+		CertificationReference: &certRef,
+		SoftwareComponents: &[]PSASwComponent{
+			{
+				MeasurementType:  &mtype,
+				MeasurementValue: bytes.Repeat([]byte{0x04}, 32),
+				Version:          &version,
+				SignerID:         bytes.Repeat([]byte{0x05}, 32),
+				MeasurementDesc:  &desc,
+			},
+		},
+		VerificationService: &vsi,
+	}
+}
+
+func TestPSAToken_RoundTrip(t *testing.T) {
+	tv := newTestPSAToken(t)
+	require.Nil(t, tv.Validate())
+
+	data, err := tv.ToCBOR()
+	require.Nil(t, err)
+
+	var actual PSAToken
+	require.Nil(t, actual.FromCBOR(data))
+	assert.Equal(t, tv, actual)
+
+	// the shared claims are visible through Eat, and satisfy the registered
+	// PSA profile
+	var e Eat
+	require.Nil(t, e.FromCBOR(data))
+	assert.Equal(t, tv.ToEat(), e)
+	assert.Nil(t, e.Validate())
+}
+
+func TestPSAToken_Validate_optional_claims(t *testing.T) {
+	tv := newTestPSAToken(t)
+
+	tv.VerificationService = nil
+	tv.CertificationReference = nil
+	tv.BootSeed = nil
+	assert.Nil(t, tv.Validate())
+}
+
+func TestPSAToken_Validate_certification_reference(t *testing.T) {
+	tv := newTestPSAToken(t)
+
+	for _, certRef := range []string{"1234567890123", "1234567890123-12345", "1234567890123_12345"} {
+		tv.CertificationReference = &certRef
+		assert.Nil(t, tv.Validate(), certRef)
+	}
+
+	certRef := "1234567890123+12345"
+	tv.CertificationReference = &certRef
+	assert.EqualError(t, tv.Validate(),
+		`psa-certification-reference: "1234567890123+12345" is not an EAN-13 or EAN-13+5`)
+}
+
+func TestPSAToken_Validate_NG(t *testing.T) {
+	tv := newTestPSAToken(t)
+
+	lifecycle := PSALifecycle(0x7001)
+	implID := []byte{0x01}
+	certRef := "123"
+	instanceID := UEID(append([]byte{UEIDTypeRAND}, bytes.Repeat([]byte{0x02}, 16)...))
+
+	tv.Profile = nil
+	tv.SecurityLifecycle = &lifecycle
+	tv.ImplementationID = &implID
+	tv.CertificationReference = &certRef
+	tv.InstanceID = &instanceID
+	tv.ClientID = nil
+	(*tv.SoftwareComponents)[0].SignerID = nil
+	require.Nil(t, tv.Nonce.Add(bytes.Repeat([]byte{0x01}, 32)))
+
+	assert.EqualError(t, tv.Validate(),
+		"eat_profile: missing mandatory claim\n"+
+			"eat_nonce: exactly one nonce is required, found 2\n"+
+			"ueid: instance ID must be a 33 bytes RAND UEID\n"+
+			"psa-client-id: missing mandatory claim\n"+
+			"psa-security-lifecycle: unknown security lifecycle state 0x7000\n"+
+			"psa-implementation-id: size must be 32 bytes; found 1\n"+
+			`psa-certification-reference: "123" is not an EAN-13 or EAN-13+5`+"\n"+
+			"psa-software-components[0]: signer ID: size must be 32, 48 or 64 bytes; found 0",
+	)
+}
+
+func TestPSAToken_FromEat(t *testing.T) {
+	tv := newTestPSAToken(t)
+
+	var actual PSAToken
+	actual.FromEat(tv.ToEat())
+
+	assert.Equal(t, tv.Profile, actual.Profile)
+	assert.Equal(t, tv.Nonce, actual.Nonce)
+	assert.Equal(t, tv.InstanceID, actual.InstanceID)
+	assert.Nil(t, actual.ClientID)
+}
+
+func TestPSAToken_MeasuredComponents(t *testing.T) {
+	tv := newTestPSAToken(t)
+
+	mcs, err := tv.MeasuredComponents()
+	require.Nil(t, err)
+	require.Len(t, mcs, 1)
+
+	mc := mcs[0]
+	assert.Nil(t, mc.Validate())
+	assert.Equal(t, "BL", mc.Id.Name)
+	assert.Equal(t, "2.1.0", mc.Id.Version.Version)
+	assert.Equal(t, uint64(swid.Sha256), mc.Measurement.Alg)
+
+	var actual PSAToken
+	require.Nil(t, actual.SetMeasuredComponents(mcs))
+	assert.Equal(t, tv.SoftwareComponents, actual.SoftwareComponents)
+
+	// without a description the algorithm is inferred from the size
+	(*tv.SoftwareComponents)[0].MeasurementDesc = nil
+	(*tv.SoftwareComponents)[0].MeasurementValue = bytes.Repeat([]byte{0x04}, 48)
+	mcs, err = tv.MeasuredComponents()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(swid.Sha384), mcs[0].Measurement.Alg)
+
+	// measurement-type is optional
+	(*tv.SoftwareComponents)[0].MeasurementType = nil
+	mcs, err = tv.MeasuredComponents()
+	require.Nil(t, err)
+	assert.Equal(t, PSADefaultComponentName, mcs[0].Id.Name)
+	require.Nil(t, actual.SetMeasuredComponents(mcs))
+	assert.Nil(t, (*actual.SoftwareComponents)[0].MeasurementType)
+
+	mc.Signers = nil
+	assert.EqualError(t, actual.SetMeasuredComponents([]MeasuredComponent{mc}),
+		"measured component at index 0: exactly one signer is required")
+}
+
+func TestPSALifecycle_String(t *testing.T) {
+	assert.Equal(t, "secured-0x01", PSALifecycle(0x3001).String())
+	assert.Equal(t, "decommissioned-0x00", PSALifecycleDecommissioned.String())
+	assert.Equal(t, "invalid-0x7000", PSALifecycle(0x7000).String())
+	assert.Equal(t, PSALifecycleNonPSARoTDebug, PSALifecycle(0x40ff).State())
+}
+
+func TestPSAProfile_Eat(t *testing.T) {
+	tv := newTestPSAToken(t)
+	require.Nil(t, tv.Nonce.Add(bytes.Repeat([]byte{0x01}, 16)))
+
+	e := tv.ToEat()
+	e.UEID = nil
+
+	assert.EqualError(t, e.Validate(),
+		"profile "+PSAProfileID+": missing required claim ueid\n"+
+			"eat_nonce: exactly one nonce is required, found 2",
+	)
+}
@@ -0,0 +1,327 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	cose "github.com/veraison/go-cose"
+)
+
+type signOptions struct {
+	kid          []byte
+	x5chain      [][]byte
+	cwtTag       bool
+	headerClaims []int64
+}
+
+// SignOption sets optional parameters of Eat.Sign
+type SignOption func(*signOptions)
+
+// WithKeyID sets the kid parameter in the unprotected header of the signed
+// token
+func WithKeyID(kid []byte) SignOption {
+	return func(o *signOptions) {
+		o.kid = kid
+	}
+}
+
+// WithX5Chain sets the x5chain parameter (RFC 9360) in the protected header
+// of the signed token to the supplied DER encoded certificates, starting with
+// the one of the signing key
+func WithX5Chain(certs ...[]byte) SignOption {
+	return func(o *signOptions) {
+		o.x5chain = append(o.x5chain, certs...)
+	}
+}
+
+// WithCWTTag wraps the signed token in a CWT tag (61)
+func WithCWTTag() SignOption {
+	return func(o *signOptions) {
+		o.cwtTag = true
+	}
+}
+
+// WithHeaderClaims mirrors the claims with the supplied keys (e.g.,
+// cose.CWTClaimIssuer) in the CWT Claims parameter of the protected header
+// (RFC 9597), so that they can be inspected without decoding the payload.
+// All the claims must be present in the Eat.
+func WithHeaderClaims(keys ...int64) SignOption {
+	return func(o *signOptions) {
+		o.headerClaims = append(o.headerClaims, keys...)
+	}
+}
+
+// Sign serializes the receiver Eat and signs it with the supplied signer,
+// returning a COSE_Sign1 message.  A cnf claim, in the Eat or in a nested
+// submod, that would disclose a private or symmetric key is refused.
+//
+//nolint:gocritic
+func (e Eat) Sign(rand io.Reader, signer cose.Signer, opts ...SignOption) ([]byte, error) {
+	var o signOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := e.validateCnf(); err != nil {
+		return nil, err
+	}
+
+	payload, err := e.ToCBOR()
+	if err != nil {
+		return nil, fmt.Errorf("encoding claims-set: %w", err)
+	}
+
+	msg := cose.NewSign1Message()
+	msg.Headers.Protected.SetAlgorithm(signer.Algorithm())
+	msg.Payload = payload
+
+	if len(o.headerClaims) > 0 {
+		claims, err := selectHeaderClaims(payload, o.headerClaims)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := msg.Headers.Protected.SetCWTClaims(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	switch len(o.x5chain) {
+	case 0:
+	case 1:
+		msg.Headers.Protected[cose.HeaderLabelX5Chain] = o.x5chain[0]
+	default:
+		msg.Headers.Protected[cose.HeaderLabelX5Chain] = o.x5chain
+	}
+
+	if o.kid != nil {
+		msg.Headers.Unprotected[cose.HeaderLabelKeyID] = o.kid
+	}
+
+	if err := msg.Sign(rand, nil, signer); err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	data, err := msg.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.cwtTag {
+		return em.Marshal(cbor.RawTag{Number: cborTagCWT, Content: data})
+	}
+
+	return data, nil
+}
+
+func selectHeaderClaims(payload []byte, keys []int64) (cose.CWTClaims, error) {
+	var claims map[int64]cbor.RawMessage
+	if err := dm.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("decoding claims-set: %w", err)
+	}
+
+	selected := cose.CWTClaims{}
+
+	for _, k := range keys {
+		v, ok := claims[k]
+		if !ok {
+			return nil, fmt.Errorf("header claim %d not found in claims-set", k)
+		}
+		selected[k] = v
+	}
+
+	return selected, nil
+}
+
+// VerifiedToken is the result of the successful verification of a signed EAT
+type VerifiedToken struct {
+	// Eat is the verified claims-set
+	Eat *Eat
+	// HeaderClaims holds the CWT claims carried in the protected header
+	// (RFC 9597), or nil if there are none
+	HeaderClaims *CWTClaims
+	// Headers are the COSE headers of the token
+	Headers cose.Headers
+	// SignerHeaders are the headers of the valid signatures of a COSE_Sign
+	// message, or nil for other tokens
+	SignerHeaders []cose.Headers
+}
+
+// Verify verifies the supplied COSE_Sign1 message, optionally wrapped in a CWT
+// tag, with the key found by the supplied resolver and decodes its claims-set.
+// If the protected header carries CWT claims (RFC 9597), they are returned
+// alongside the claims-set, and any claim that is also present in the payload
+// must have the same value in both.  A COSE_Sign message is also accepted, in
+// which case all its signatures must be valid (see VerifyMulti).
+func Verify(data []byte, resolver KeyResolver) (*VerifiedToken, error) {
+	if isCOSESign(data) {
+		return VerifyMulti(data, resolver, SignaturePolicyAll)
+	}
+
+	msg, err := decodeSign1(data)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := parseCBORClaims(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := resolveVerifier(resolver, msg.Headers, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := msg.Verify(nil, verifier); err != nil {
+		return nil, fmt.Errorf("verifying signature: %w", err)
+	}
+
+	return newVerifiedToken(msg.Headers, msg.Payload, e)
+}
+
+// resolveVerifier returns a verifier for the algorithm in the supplied headers
+// with the key found by the supplied resolver
+func resolveVerifier(resolver KeyResolver, headers cose.Headers, e *Eat) (cose.Verifier, error) {
+	if resolver == nil {
+		return nil, errors.New("no key resolver")
+	}
+
+	verifier, _, err := resolveVerifierKey(resolver, headers, e)
+
+	return verifier, err
+}
+
+// resolveVerifierKey is resolveVerifier, also returning the resolved key
+func resolveVerifierKey(resolver KeyResolver, headers cose.Headers, e *Eat) (cose.Verifier, crypto.PublicKey, error) {
+	alg, err := headers.Protected.Algorithm()
+	if err != nil {
+		return nil, nil, fmt.Errorf("signature algorithm: %w", err)
+	}
+
+	key, err := resolver.ResolveKey(newKeyQuery(headers, alg, e))
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving key: %w", err)
+	}
+
+	verifier, err := cose.NewVerifier(alg, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving key: %w", err)
+	}
+
+	return verifier, key, nil
+}
+
+func decodeSign1(data []byte) (*cose.Sign1Message, error) {
+	data = skipCWTTag(data)
+
+	var msg cose.Sign1Message
+
+	if isCBORTag(data) {
+		if err := msg.UnmarshalCBOR(data); err != nil {
+			return nil, fmt.Errorf("decoding COSE_Sign1: %w", err)
+		}
+	} else {
+		var untagged cose.UntaggedSign1Message
+		if err := untagged.UnmarshalCBOR(data); err != nil {
+			return nil, fmt.Errorf("decoding COSE_Sign1: %w", err)
+		}
+		msg = cose.Sign1Message(untagged)
+	}
+
+	if msg.Payload == nil {
+		return nil, errors.New("detached COSE payload")
+	}
+
+	return &msg, nil
+}
+
+func newVerifiedToken(headers cose.Headers, payload []byte, e *Eat) (*VerifiedToken, error) {
+	t := VerifiedToken{Eat: e, Headers: headers}
+
+	if _, ok := headers.Unprotected[cose.HeaderLabelCWTClaims]; ok {
+		return nil, errors.New("CWT claims header parameter must be protected")
+	}
+
+	v, ok := headers.Protected[cose.HeaderLabelCWTClaims]
+	if !ok {
+		return &t, nil
+	}
+
+	raw, err := em.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding CWT claims header parameter: %w", err)
+	}
+
+	if err := checkHeaderClaims(raw, payload); err != nil {
+		return nil, err
+	}
+
+	var hc CWTClaims
+	if err := dm.Unmarshal(raw, &hc); err != nil {
+		return nil, fmt.Errorf("decoding CWT claims header parameter: %w", err)
+	}
+
+	t.HeaderClaims = &hc
+
+	return &t, nil
+}
+
+// checkHeaderClaims checks that the claims in the CWT claims header parameter
+// have the same value as in the payload, if present there
+func checkHeaderClaims(header, payload []byte) error {
+	var hc, pc map[int64]cbor.RawMessage
+
+	if err := dm.Unmarshal(header, &hc); err != nil {
+		return fmt.Errorf("decoding CWT claims header parameter: %w", err)
+	}
+
+	if err := dm.Unmarshal(payload, &pc); err != nil {
+		return fmt.Errorf("decoding claims-set: %w", err)
+	}
+
+	keys := make([]int64, 0, len(hc))
+	for k := range hc {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, k := range keys {
+		p, ok := pc[k]
+		if !ok {
+			continue
+		}
+
+		hv, err := canonicalCBOR(hc[k])
+		if err != nil {
+			return fmt.Errorf("header claim %d: %w", k, err)
+		}
+
+		pv, err := canonicalCBOR(p)
+		if err != nil {
+			return fmt.Errorf("claim %d: %w", k, err)
+		}
+
+		if !bytes.Equal(hv, pv) {
+			return fmt.Errorf("header claim %d does not match the claims-set", k)
+		}
+	}
+
+	return nil
+}
+
+func canonicalCBOR(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := dm.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return em.Marshal(v)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func newTestSignerResolver(t *testing.T) (cose.Signer, KeyResolver) {
+	key := newTestP256Key(t)
+
+	signer, err := cose.NewSigner(cose.AlgorithmES256, key)
+	require.Nil(t, err)
+
+	return signer, StaticKeyResolver(key.Public())
+}
+
+func newTestSignedClaims(t *testing.T) Eat {
+	e := newTestClaims(t)
+
+	iss := "https://attester.example"
+	iat := NumericDate(time.Unix(1694498816, 0))
+	e.Issuer = &iss
+	e.IssuedAt = &iat
+
+	return e
+}
+
+func TestSign_Verify_HeaderClaims(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	for _, opts := range [][]SignOption{
+		{WithHeaderClaims(cose.CWTClaimIssuer, cose.CWTClaimIssuedAt)},
+		{WithHeaderClaims(cose.CWTClaimIssuer, cose.CWTClaimIssuedAt), WithCWTTag(), WithKeyID([]byte("kid"))},
+	} {
+		data, err := e.Sign(rand.Reader, signer, opts...)
+		require.Nil(t, err)
+
+		actual, err := Verify(data, resolver)
+		require.Nil(t, err)
+		assert.Equal(t, e.Nonce, actual.Eat.Nonce)
+		require.NotNil(t, actual.HeaderClaims)
+		assert.Equal(t, e.Issuer, actual.HeaderClaims.Issuer)
+		require.NotNil(t, actual.HeaderClaims.IssuedAt)
+		assert.True(t, time.Time(*e.IssuedAt).Equal(time.Time(*actual.HeaderClaims.IssuedAt)))
+		assert.Nil(t, actual.HeaderClaims.Subject)
+	}
+}
+
+func TestSign_Verify_no_HeaderClaims(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	data, err := e.Sign(rand.Reader, signer)
+	require.Nil(t, err)
+
+	actual, err := Verify(data, resolver)
+	require.Nil(t, err)
+	assert.Nil(t, actual.HeaderClaims)
+	assert.Equal(t, e.Issuer, actual.Eat.Issuer)
+}
+
+func TestSign_missing_HeaderClaim(t *testing.T) {
+	signer, _ := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	_, err := e.Sign(rand.Reader, signer, WithHeaderClaims(cose.CWTClaimSubject))
+	assert.EqualError(t, err, "header claim 2 not found in claims-set")
+}
+
+func TestVerify_HeaderClaims_mismatch(t *testing.T) {
+	signer, resolver := newTestSignerResolver(t)
+	e := newTestSignedClaims(t)
+
+	payload, err := e.ToCBOR()
+	require.Nil(t, err)
+
+	msg := cose.NewSign1Message()
+	msg.Headers.Protected.SetAlgorithm(cose.AlgorithmES256)
+	msg.Headers.Protected[cose.HeaderLabelCWTClaims] = cose.CWTClaims{
+		cose.CWTClaimIssuer: "https://other.example",
+	}
+	msg.Payload = payload
+	require.Nil(t, msg.Sign(rand.Reader, nil, signer))
+
+	data, err := msg.MarshalCBOR()
+	require.Nil(t, err)
+
+	_, err = Verify(data, resolver)
+	assert.EqualError(t, err, "header claim 1 does not match the claims-set")
+}
+
+func TestVerify_bad_signature(t *testing.T) {
+	signer, _ := newTestSignerResolver(t)
+	_, resolver := newTestSignerResolver(t)
+
+	data, err := newTestSignedClaims(t).Sign(rand.Reader, signer)
+	require.Nil(t, err)
+
+	_, err = Verify(data, resolver)
+	assert.ErrorContains(t, err, "verifying signature")
+}
@@ -5,17 +5,20 @@ package eat
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 )
 
-// Submod is the type of a submod: either a raw EAT (wrapped in a Sign1 CWT), or
-// a map of EAT claims
+// Submod is the type of a submod: either a raw EAT (a signed or encrypted
+// CWT), or a map of EAT claims
 type Submod struct{ value interface{} }
 
-// MarshalJSON encodes the submod value wrapped in the Submod receiver to JSON
+// MarshalJSON encodes the submod value wrapped in the Submod receiver to JSON.
+// Nested tokens are encoded as base64url strings.
 func (s Submod) MarshalJSON() ([]byte, error) {
+	if t, ok := s.value.([]byte); ok {
+		return json.Marshal(encodeBinaryData(t))
+	}
 	return json.Marshal(s.value)
 }
 
@@ -42,7 +45,7 @@ func (s *Submod) UnmarshalJSON(data []byte) error {
 	// eat-token
 	b64 := string(data[1 : len(data)-1]) // remove quotes
 
-	eatToken, err := base64.StdEncoding.DecodeString(b64)
+	eatToken, err := decodeBinaryData(b64)
 	if err != nil {
 		return err
 	}
@@ -92,20 +95,33 @@ func (s *Submod) UnmarshalCBOR(data []byte) error {
 	return nil
 }
 
+// checkTags checks that the supplied nested token is a CWT wrapping either a
+// COSE_Sign1 or COSE_Sign message, or a COSE_Encrypt0 or COSE_Encrypt message
+// (encrypted EAT)
 func checkTags(data []byte) error {
 	// d8 3d  # tag(61) -- CWT
 	// d2  # tag(18) -- Sign1
-	prefix := []byte{0xd8, 0x3d, 0xd2}
+	// d8 62  # tag(98) -- Sign
+	// d0  # tag(16) -- Encrypt0
+	// d8 60  # tag(96) -- Encrypt
+	prefixes := [][]byte{
+		{0xd8, 0x3d, 0xd2},
+		{0xd8, 0x3d, 0xd8, 0x62},
+		{0xd8, 0x3d, 0xd0},
+		{0xd8, 0x3d, 0xd8, 0x60},
+	}
 
-	if len(data) < len(prefix)+1 {
+	if len(data) < len(prefixes[0])+1 {
 		return errors.New("not enough bytes")
 	}
 
-	if !bytes.HasPrefix(data, prefix) {
-		return errors.New("CWT and COSE Sign1 tags not found")
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(data, prefix) && len(data) > len(prefix) {
+			return nil
+		}
 	}
 
-	return nil
+	return errors.New("CWT and COSE Sign1, Sign, Encrypt0 or Encrypt tags not found")
 }
 
 // Submods models the submods type
@@ -37,7 +37,7 @@ func TestSubmods_Add_FAIL(t *testing.T) {
 	noTagsJustRandomStuff := []byte{0x00, 0x01, 0x02, 0x03, 0x04}
 
 	err = s.Add("eat-token", noTagsJustRandomStuff)
-	assert.EqualError(t, err, "CWT and COSE Sign1 tags not found")
+	assert.EqualError(t, err, "CWT and COSE Sign1, Sign, Encrypt0 or Encrypt tags not found")
 
 	badSubmodType := 12.34
 
@@ -53,9 +53,9 @@ func TestSubmods_JSONMarshal_Simple(t *testing.T) {
 
 	expected := `{
 		"0": {
-			"eat_nonce": "AAAAAAAAAAA="
+			"eat_nonce": "AAAAAAAAAAA"
 		},
-		"xyz": "2D3SQaA="
+		"xyz": "2D3SQaA"
 	}`
 
 	actual, err := json.Marshal(s)
@@ -75,7 +75,7 @@ func TestSubmods_JSONMarshal_Nested(t *testing.T) {
 	expected := `{
 		"0": {
 			"submods": {
-				"xyz": "2D3SQaA="
+				"xyz": "2D3SQaA"
 			}
 		}
 	}`
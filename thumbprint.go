@@ -0,0 +1,110 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	cose "github.com/veraison/go-cose"
+)
+
+// Thumbprint computes the COSE Key Thumbprint (RFC 9679) of the receiver
+// COSEKey using the supplied hash function, e.g., crypto.SHA256.  Only the
+// required public parameters of the key type are hashed, so the thumbprint of
+// a private key is the same as that of its public key.
+//
+//nolint:gocritic
+func (k COSEKey) Thumbprint(h crypto.Hash) ([]byte, error) {
+	params := map[int64]interface{}{1: int64(k.Type)}
+
+	switch k.Type {
+	case cose.KeyTypeOKP:
+		if len(k.X) == 0 {
+			return nil, errors.New("missing OKP x coordinate")
+		}
+		params[-1] = int64(k.Crv)
+		params[-2] = k.X
+	case cose.KeyTypeEC2:
+		if len(k.X) == 0 || len(k.Y) == 0 {
+			return nil, errors.New("missing EC2 coordinates")
+		}
+		params[-1] = int64(k.Crv)
+		params[-2] = k.X
+		params[-3] = k.Y
+	case KeyTypeRSA:
+		if len(k.N) == 0 || len(k.E) == 0 {
+			return nil, errors.New("missing RSA modulus or exponent")
+		}
+		params[-1] = k.N
+		params[-2] = k.E
+	case cose.KeyTypeSymmetric:
+		if len(k.K) == 0 {
+			return nil, errors.New("missing symmetric key value")
+		}
+		params[-1] = k.K
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Type)
+	}
+
+	data, err := em.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashData(h, data)
+}
+
+// JWKThumbprint computes the JWK Thumbprint (RFC 7638) of the receiver COSEKey
+// using the supplied hash function, e.g., crypto.SHA256
+//
+//nolint:gocritic
+func (k COSEKey) JWKThumbprint(h crypto.Hash) ([]byte, error) {
+	pub := COSEKey{Type: k.Type}
+
+	switch k.Type {
+	case cose.KeyTypeOKP:
+		pub.Crv, pub.X = k.Crv, k.X
+	case cose.KeyTypeEC2:
+		pub.Crv, pub.X, pub.Y = k.Crv, k.X, k.Y
+	case KeyTypeRSA:
+		pub.N, pub.E = k.N, k.E
+	case cose.KeyTypeSymmetric:
+		pub.K = k.K
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Type)
+	}
+
+	// the required members, with no whitespace and in lexicographic order,
+	// which is what encoding/json produces when marshaling a map
+	data, err := json.Marshal(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashData(h, data)
+}
+
+func hashData(h crypto.Hash, data []byte) ([]byte, error) {
+	if !h.Available() {
+		return nil, fmt.Errorf("hash function %v not available", h)
+	}
+
+	hh := h.New()
+	hh.Write(data)
+
+	return hh.Sum(nil), nil
+}
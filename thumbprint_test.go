@@ -0,0 +1,50 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cose "github.com/veraison/go-cose"
+)
+
+func TestCOSEKey_Thumbprint(t *testing.T) {
+	key := COSEKey{Type: kty, ID: kid, Crv: crv, X: x, Y: y}
+
+	tp, err := key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	assert.Equal(t, keyThumbprint, tp)
+
+	// optional and private parameters do not contribute to the thumbprint
+	key.Algorithm = cose.AlgorithmES256
+	key.D = []byte{0x01}
+
+	tp, err = key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	assert.Equal(t, keyThumbprint, tp)
+
+	_, err = COSEKey{Type: kty, Crv: crv, X: x}.Thumbprint(crypto.SHA256)
+	assert.EqualError(t, err, "missing EC2 coordinates")
+}
+
+func TestCOSEKey_JWKThumbprint(t *testing.T) {
+	key := COSEKey{Type: kty, ID: kid, Crv: crv, X: x, Y: y}
+
+	tp, err := key.JWKThumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	assert.Equal(t, "xNnfOFTMgZSRM3KtGHQqavZGWGF00Fe54LZBYCIxr88", encodeBinaryData(tp))
+
+	key.ID = nil
+	key.Ops = []cose.KeyOp{cose.KeyOpVerify}
+
+	actual, err := key.JWKThumbprint(crypto.SHA256)
+	require.Nil(t, err)
+	assert.Equal(t, tp, actual)
+
+	_, err = key.JWKThumbprint(crypto.MD4)
+	assert.EqualError(t, err, "hash function MD4 not available")
+}
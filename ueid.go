@@ -3,7 +3,14 @@
 
 package eat
 
-import "fmt"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
 
 const (
 	UEIDTypeInvalid = iota
@@ -59,7 +66,10 @@ func (u UEID) Validate() error {
 }
 
 func validateRAND(value []byte) error {
-	vlen := len(value)
+	return validateRANDSize(len(value))
+}
+
+func validateRANDSize(vlen int) error {
 	if vlen != 16 && vlen != 24 && vlen != 32 {
 		return fmt.Errorf("RAND length must be exactly 16, 24, or 32 bytes; found %v bytes", vlen)
 	}
@@ -79,5 +89,135 @@ func validateIMEI(value []byte) error {
 	if vlen != 14 {
 		return fmt.Errorf("IMEI length must be exactly 14 bytes; found %v bytes", vlen)
 	}
+	for i, b := range value {
+		if b > 9 {
+			return fmt.Errorf("IMEI digit at index %d out of range: %#x", i, b)
+		}
+	}
 	return nil
 }
+
+// NewRandUEID returns a RAND UEID carrying size bytes (16, 24 or 32) read from
+// crypto/rand
+func NewRandUEID(size int) (UEID, error) {
+	if err := validateRANDSize(size); err != nil {
+		return nil, err
+	}
+
+	u := make(UEID, 1+size)
+	u[0] = UEIDTypeRAND
+
+	if _, err := rand.Read(u[1:]); err != nil {
+		return nil, fmt.Errorf("generating random UEID: %w", err)
+	}
+
+	return u, nil
+}
+
+// UEIDFromEUI48 returns an EUI UEID from the supplied EUI-48 (MAC-48) address
+// in any of the notations accepted by net.ParseMAC, e.g., "00:00:5e:00:53:01",
+// "00-00-5E-00-53-01" or "0000.5e00.5301"
+func UEIDFromEUI48(s string) (UEID, error) {
+	return ueidFromEUI(s, 6)
+}
+
+// UEIDFromEUI64 returns an EUI UEID from the supplied EUI-64 address in any of
+// the notations accepted by net.ParseMAC, e.g., "02:00:5e:10:00:00:00:01"
+func UEIDFromEUI64(s string) (UEID, error) {
+	return ueidFromEUI(s, 8)
+}
+
+func ueidFromEUI(s string, size int) (UEID, error) {
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hw) != size {
+		return nil, fmt.Errorf("expecting EUI-%d, found %d bytes", size*8, len(hw))
+	}
+
+	return append(UEID{UEIDTypeEUI}, hw...), nil
+}
+
+// UEIDFromIMEI returns an IMEI UEID from the supplied 15-digit IMEI string.
+// The trailing Luhn check digit is verified and stripped as the encoded IMEI
+// must not include it.
+func UEIDFromIMEI(s string) (UEID, error) {
+	if len(s) != 15 {
+		return nil, fmt.Errorf("IMEI must be exactly 15 digits; found %d characters", len(s))
+	}
+
+	digits := make([]byte, 15)
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return nil, fmt.Errorf("IMEI contains non-digit character %q", s[i])
+		}
+		digits[i] = s[i] - '0'
+	}
+
+	if check := luhnCheckDigit(digits[:14]); check != digits[14] {
+		return nil, fmt.Errorf("IMEI Luhn check digit mismatch: want %d, found %d", check, digits[14])
+	}
+
+	return append(UEID{UEIDTypeIMEI}, digits[:14]...), nil
+}
+
+// luhnCheckDigit computes the Luhn check digit of the supplied digit values
+func luhnCheckDigit(digits []byte) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i])
+		if (len(digits)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte((10 - sum%10) % 10)
+}
+
+// String returns a human-readable rendering of the receiver UEID:
+//
+//   - RAND: "rand:" followed by the hex-encoded value
+//   - EUI:  "eui48:" or "eui64:" followed by the address in colon notation
+//   - IMEI: "imei:" followed by the 15-digit IMEI (including the Luhn check
+//     digit)
+//
+// UEIDs that do not validate are rendered as "invalid:" followed by the
+// hex-encoding of the whole UEID.
+func (u UEID) String() string {
+	if err := u.Validate(); err != nil {
+		return "invalid:" + hex.EncodeToString(u)
+	}
+
+	value := u[1:]
+
+	switch u[0] {
+	case UEIDTypeRAND:
+		return "rand:" + hex.EncodeToString(value)
+	case UEIDTypeEUI:
+		return fmt.Sprintf("eui%d:%s", len(value)*8, net.HardwareAddr(value))
+	default: // UEIDTypeIMEI
+		var sb strings.Builder
+		sb.WriteString("imei:")
+		for _, d := range value {
+			sb.WriteByte('0' + d)
+		}
+		sb.WriteByte('0' + luhnCheckDigit(value))
+		return sb.String()
+	}
+}
+
+// MarshalJSON encodes the receiver UEID as a base64url JSON string
+func (u UEID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeBinaryData(u))
+}
+
+// UnmarshalJSON decodes a base64url (or standard base64) JSON string into the
+// receiver UEID
+func (u *UEID) UnmarshalJSON(data []byte) error {
+	return (*binaryData)(u).UnmarshalJSON(data)
+}
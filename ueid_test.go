@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUEID_Verify(t *testing.T) {
@@ -42,8 +43,8 @@ func TestUEID_Verify(t *testing.T) {
 
 	u5 := UEID{
 		0x03, // IMEI
-		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef,
-		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, // 14 bytes
+		0x03, 0x05, 0x03, 0x09, 0x03, 0x09, 0x00, 0x00,
+		0x02, 0x00, 0x04, 0x06, 0x03, 0x05, // 14 bytes
 	}
 	assert.Nil(t, u5.Validate())
 
@@ -60,4 +61,71 @@ func TestUEID_Verify(t *testing.T) {
 	}
 	assert.EqualError(t, u7.Validate(), "invalid UEID type 255")
 
+	u8 := UEID{
+		0x03, // IMEI
+		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, 0xbe, 0xef,
+		0xde, 0xad, 0xbe, 0xef, 0xde, 0xad, // 14 bytes
+	}
+	assert.EqualError(t, u8.Validate(), "IMEI digit at index 0 out of range: 0xde")
+
+}
+
+func TestNewRandUEID(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		u, err := NewRandUEID(size)
+		require.Nil(t, err)
+		assert.Len(t, u, size+1)
+		assert.Equal(t, byte(UEIDTypeRAND), u[0])
+		assert.Nil(t, u.Validate())
+	}
+
+	_, err := NewRandUEID(8)
+	assert.EqualError(t, err, "RAND length must be exactly 16, 24, or 32 bytes; found 8 bytes")
+
+	_, err = NewRandUEID(-1)
+	assert.EqualError(t, err, "RAND length must be exactly 16, 24, or 32 bytes; found -1 bytes")
+}
+
+func TestUEIDFromEUI(t *testing.T) {
+	u, err := UEIDFromEUI48("00-00-5E-00-53-01")
+	require.Nil(t, err)
+	assert.Equal(t, UEID{0x02, 0x00, 0x00, 0x5e, 0x00, 0x53, 0x01}, u)
+	assert.Equal(t, "eui48:00:00:5e:00:53:01", u.String())
+
+	u, err = UEIDFromEUI64("02:00:5e:10:00:00:00:01")
+	require.Nil(t, err)
+	assert.Equal(t, UEID{0x02, 0x02, 0x00, 0x5e, 0x10, 0x00, 0x00, 0x00, 0x01}, u)
+	assert.Equal(t, "eui64:02:00:5e:10:00:00:00:01", u.String())
+
+	_, err = UEIDFromEUI48("02:00:5e:10:00:00:00:01")
+	assert.EqualError(t, err, "expecting EUI-48, found 8 bytes")
+
+	_, err = UEIDFromEUI64("not-a-mac")
+	assert.NotNil(t, err)
+}
+
+func TestUEIDFromIMEI(t *testing.T) {
+	u, err := UEIDFromIMEI("490154203237518")
+	require.Nil(t, err)
+	assert.Equal(t, UEID{
+		0x03,
+		0x04, 0x09, 0x00, 0x01, 0x05, 0x04, 0x02, 0x00,
+		0x03, 0x02, 0x03, 0x07, 0x05, 0x01,
+	}, u)
+	assert.Equal(t, "imei:490154203237518", u.String())
+
+	_, err = UEIDFromIMEI("490154203237519")
+	assert.EqualError(t, err, "IMEI Luhn check digit mismatch: want 8, found 9")
+
+	_, err = UEIDFromIMEI("49015420323751")
+	assert.EqualError(t, err, "IMEI must be exactly 15 digits; found 14 characters")
+
+	_, err = UEIDFromIMEI("49015420323751x")
+	assert.EqualError(t, err, `IMEI contains non-digit character 'x'`)
+}
+
+func TestUEID_String(t *testing.T) {
+	assert.Equal(t, "rand:deadbeefdeadbeefdeadbeefdeadbeef", ueID.String())
+	assert.Equal(t, "invalid:ff00", UEID{0xff, 0x00}.String())
+	assert.Equal(t, "invalid:", UEID{}.String())
 }
@@ -0,0 +1,288 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/veraison/swid"
+)
+
+var (
+	multipartNumericRe       = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+	multipartNumericSuffixRe = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)*)(.*)$`)
+	decimalRe                = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+	semverRe                 = regexp.MustCompile(
+		`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+			`(?:-((?:0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+			`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`,
+	)
+)
+
+// versionSchemeCode returns the code point of the supplied version scheme, or
+// zero if it is nil
+func versionSchemeCode(vs *swid.VersionScheme) int64 {
+	if vs == nil {
+		return 0
+	}
+
+	switch vs.String() {
+	case "multipartnumeric":
+		return swid.VersionSchemeMultipartNumeric
+	case "multipartnumeric+suffix":
+		return swid.VersionSchemeMultipartNumericSuffix
+	case "alphanumeric":
+		return swid.VersionSchemeAlphaNumeric
+	case "decimal":
+		return swid.VersionSchemeDecimal
+	case "semver":
+		return swid.VersionSchemeSemVer
+	default:
+		return swid.VersionSchemeUnknown
+	}
+}
+
+// Validate checks that the receiver Version is not empty and, if a version
+// scheme is set, that the version string is well-formed according to it.
+// Unknown (e.g., private) version schemes are not checked.
+func (v Version) Validate() error {
+	if v.Version == "" {
+		return errors.New("empty version")
+	}
+
+	code := versionSchemeCode(v.Scheme)
+	if code == 0 || code == swid.VersionSchemeUnknown {
+		return nil
+	}
+
+	return validateVersionString(v.Version, code)
+}
+
+func validateVersionString(s string, scheme int64) error {
+	var re *regexp.Regexp
+
+	switch scheme {
+	case swid.VersionSchemeMultipartNumeric:
+		re = multipartNumericRe
+	case swid.VersionSchemeMultipartNumericSuffix:
+		re = multipartNumericSuffixRe
+	case swid.VersionSchemeAlphaNumeric:
+		if s == "" {
+			return errors.New("empty version")
+		}
+		return nil
+	case swid.VersionSchemeDecimal:
+		re = decimalRe
+	case swid.VersionSchemeSemVer:
+		re = semverRe
+	default:
+		return fmt.Errorf("unsupported version scheme %d", scheme)
+	}
+
+	if !re.MatchString(s) {
+		return fmt.Errorf("%q is not a valid %s version", s, versionSchemeName(scheme))
+	}
+
+	return nil
+}
+
+func versionSchemeName(scheme int64) string {
+	var vs swid.VersionScheme
+	if err := vs.SetCode(scheme); err != nil {
+		return fmt.Sprintf("version-scheme(%d)", scheme)
+	}
+	return vs.String()
+}
+
+// Compare compares the receiver Version with other according to their version
+// scheme and returns -1, 0 or +1 if the receiver is respectively lower than,
+// equal to, or greater than other.  Both versions must carry the same known
+// version scheme.
+func (v Version) Compare(other Version) (int, error) {
+	a, b := versionSchemeCode(v.Scheme), versionSchemeCode(other.Scheme)
+
+	if a == 0 || b == 0 {
+		return 0, errors.New("cannot compare versions without a version scheme")
+	}
+
+	if a != b {
+		return 0, fmt.Errorf(
+			"cannot compare versions with different schemes: %s and %s",
+			versionSchemeName(a), versionSchemeName(b),
+		)
+	}
+
+	return CompareVersionStrings(v.Version, other.Version, a)
+}
+
+// CompareString is like Compare except the other version is supplied as a
+// string and interpreted according to the version scheme of the receiver.
+// This allows policies such as "swversion >= 2.4.1" to be expressed as:
+//
+//	c, err := swversion.CompareString("2.4.1")
+//	ok := err == nil && c >= 0
+func (v Version) CompareString(other string) (int, error) {
+	return v.Compare(Version{Version: other, Scheme: v.Scheme})
+}
+
+// CompareVersionStrings compares the version strings a and b according to
+// the supplied CoSWID version scheme code point (e.g.,
+// swid.VersionSchemeSemVer) and returns -1, 0 or +1 if a is respectively lower
+// than, equal to, or greater than b.  An error is returned if either version
+// is not well-formed according to the scheme.
+//
+// The ordering rules are:
+//
+//   - multipartnumeric: parts are compared numerically from left to right,
+//     missing trailing parts count as zero (so that "1.2" == "1.2.0")
+//   - multipartnumeric+suffix: the numeric parts are compared as above, then
+//     the suffixes are compared lexically, a missing suffix sorting first
+//   - alphanumeric: byte-wise lexical comparison
+//   - decimal: numeric comparison of the (arbitrary precision) values
+//   - semver: precedence as defined by Semantic Versioning 2.0.0, ignoring
+//     build metadata
+func CompareVersionStrings(a, b string, scheme int64) (int, error) {
+	if err := validateVersionString(a, scheme); err != nil {
+		return 0, err
+	}
+
+	if err := validateVersionString(b, scheme); err != nil {
+		return 0, err
+	}
+
+	switch scheme {
+	case swid.VersionSchemeMultipartNumeric:
+		return compareMultipartNumeric(a, b), nil
+	case swid.VersionSchemeMultipartNumericSuffix:
+		ma := multipartNumericSuffixRe.FindStringSubmatch(a)
+		mb := multipartNumericSuffixRe.FindStringSubmatch(b)
+		if c := compareMultipartNumeric(ma[1], mb[1]); c != 0 {
+			return c, nil
+		}
+		return strings.Compare(ma[2], mb[2]), nil
+	case swid.VersionSchemeAlphaNumeric:
+		return strings.Compare(a, b), nil
+	case swid.VersionSchemeDecimal:
+		return compareDecimal(a, b)
+	default: // swid.VersionSchemeSemVer
+		return compareSemVer(a, b), nil
+	}
+}
+
+func compareMultipartNumeric(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		na, nb := "0", "0"
+		if i < len(pa) {
+			na = pa[i]
+		}
+		if i < len(pb) {
+			nb = pb[i]
+		}
+		if c := compareNumericStrings(na, nb); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compareNumericStrings compares two strings of decimal digits by numeric
+// value without risking integer overflow
+func compareNumericStrings(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareDecimal(a, b string) (int, error) {
+	ra, ok := new(big.Rat).SetString(a)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a valid decimal version", a)
+	}
+
+	rb, ok := new(big.Rat).SetString(b)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a valid decimal version", b)
+	}
+
+	return ra.Cmp(rb), nil
+}
+
+func compareSemVer(a, b string) int {
+	ma, mb := semverRe.FindStringSubmatch(a), semverRe.FindStringSubmatch(b)
+
+	for i := 1; i <= 3; i++ {
+		if c := compareNumericStrings(ma[i], mb[i]); c != 0 {
+			return c
+		}
+	}
+
+	preA, preB := ma[4], mb[4]
+
+	// a version without pre-release has higher precedence than one with
+	switch {
+	case preA == "" && preB == "":
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+
+	ida, idb := strings.Split(preA, "."), strings.Split(preB, ".")
+
+	for i := 0; i < len(ida) && i < len(idb); i++ {
+		if c := compareSemVerIdentifiers(ida[i], idb[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(ida) < len(idb):
+		return -1
+	case len(ida) > len(idb):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareSemVerIdentifiers(a, b string) int {
+	numA, numB := isNumericIdentifier(a), isNumericIdentifier(b)
+
+	switch {
+	case numA && numB:
+		return compareNumericStrings(a, b)
+	case numA:
+		// numeric identifiers have lower precedence than alphanumeric ones
+		return -1
+	case numB:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func isNumericIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package eat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/swid"
+)
+
+func newVersionScheme(t *testing.T, code int64) *swid.VersionScheme {
+	var vs swid.VersionScheme
+	require.Nil(t, vs.SetCode(code))
+	return &vs
+}
+
+func TestVersion_Validate(t *testing.T) {
+	tests := []struct {
+		version  string
+		scheme   int64
+		expected string
+	}{
+		{"1.3.4", swid.VersionSchemeMultipartNumeric, ""},
+		{"1.3.4-beta", swid.VersionSchemeMultipartNumeric, `"1.3.4-beta" is not a valid multipartnumeric version`},
+		{"1.3.4-beta", swid.VersionSchemeMultipartNumericSuffix, ""},
+		{"beta", swid.VersionSchemeMultipartNumericSuffix, `"beta" is not a valid multipartnumeric+suffix version`},
+		{"v1beta2", swid.VersionSchemeAlphaNumeric, ""},
+		{"134", swid.VersionSchemeDecimal, ""},
+		{"1.34", swid.VersionSchemeDecimal, ""},
+		{"1.3.4", swid.VersionSchemeDecimal, `"1.3.4" is not a valid decimal version`},
+		{"1.3.4-rc.1+build.5", swid.VersionSchemeSemVer, ""},
+		{"01.3.4", swid.VersionSchemeSemVer, `"01.3.4" is not a valid semver version`},
+		{"1.3", swid.VersionSchemeSemVer, `"1.3" is not a valid semver version`},
+	}
+
+	for _, test := range tests {
+		v := Version{Version: test.version, Scheme: newVersionScheme(t, test.scheme)}
+		err := v.Validate()
+		if test.expected == "" {
+			assert.Nil(t, err, test.version)
+		} else {
+			assert.EqualError(t, err, test.expected)
+		}
+	}
+
+	assert.Nil(t, Version{Version: "anything goes"}.Validate())
+	assert.EqualError(t, Version{}.Validate(), "empty version")
+}
+
+func TestCompareVersionStrings(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		scheme   int64
+		expected int
+	}{
+		{"1.2.3", "1.2.3", swid.VersionSchemeMultipartNumeric, 0},
+		{"1.2", "1.2.0", swid.VersionSchemeMultipartNumeric, 0},
+		{"1.10", "1.9", swid.VersionSchemeMultipartNumeric, 1},
+		{"1.2.3", "1.2.3.1", swid.VersionSchemeMultipartNumeric, -1},
+		{"99999999999999999999999.1", "99999999999999999999999.2", swid.VersionSchemeMultipartNumeric, -1},
+		{"1.1.1", "1.1.1a", swid.VersionSchemeMultipartNumericSuffix, -1},
+		{"1.1.1b", "1.1.1a", swid.VersionSchemeMultipartNumericSuffix, 1},
+		{"1.1.2", "1.1.1z", swid.VersionSchemeMultipartNumericSuffix, 1},
+		{"abc", "abd", swid.VersionSchemeAlphaNumeric, -1},
+		{"1.5", "1.50", swid.VersionSchemeDecimal, 0},
+		{"10", "9.99", swid.VersionSchemeDecimal, 1},
+		{"1.0.0-alpha", "1.0.0", swid.VersionSchemeSemVer, -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", swid.VersionSchemeSemVer, -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", swid.VersionSchemeSemVer, -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", swid.VersionSchemeSemVer, -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", swid.VersionSchemeSemVer, 1},
+		{"1.0.0+build.1", "1.0.0+build.2", swid.VersionSchemeSemVer, 0},
+		{"2.4.10", "2.4.9", swid.VersionSchemeSemVer, 1},
+	}
+
+	for _, test := range tests {
+		actual, err := CompareVersionStrings(test.a, test.b, test.scheme)
+		require.Nil(t, err, "%s vs %s", test.a, test.b)
+		assert.Equal(t, test.expected, actual, "%s vs %s", test.a, test.b)
+	}
+
+	_, err := CompareVersionStrings("1.2", "1.2.3", swid.VersionSchemeSemVer)
+	assert.EqualError(t, err, `"1.2" is not a valid semver version`)
+
+	_, err = CompareVersionStrings("1", "2", 42)
+	assert.EqualError(t, err, "unsupported version scheme 42")
+}
+
+func TestVersion_Compare(t *testing.T) {
+	semver := newVersionScheme(t, swid.VersionSchemeSemVer)
+	multipart := newVersionScheme(t, swid.VersionSchemeMultipartNumeric)
+
+	swversion := Version{Version: "2.4.1", Scheme: semver}
+
+	c, err := swversion.CompareString("2.4.1")
+	require.Nil(t, err)
+	assert.Equal(t, 0, c)
+
+	c, err = swversion.Compare(Version{Version: "2.5.0", Scheme: semver})
+	require.Nil(t, err)
+	assert.Equal(t, -1, c)
+
+	_, err = swversion.Compare(Version{Version: "2.4.1", Scheme: multipart})
+	assert.EqualError(t, err, "cannot compare versions with different schemes: semver and multipartnumeric")
+
+	_, err = swversion.Compare(Version{Version: "2.4.1"})
+	assert.EqualError(t, err, "cannot compare versions without a version scheme")
+}